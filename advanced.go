@@ -1,16 +1,16 @@
 package goserversdk
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
 )
 
 // AdvancedService 高级功能服务
 type AdvancedService struct {
 	client *Client
+	logger Logger
 }
 
 // CIDType CID类型
@@ -93,31 +93,20 @@ func (s *AdvancedService) GetCID(count int, cidType CIDType) (*CIDResponse, erro
 	}
 
 	// 构建查询参数
-	params := make(map[string]string)
-	params["count"] = strconv.Itoa(count)
+	params := url.Values{}
+	params.Set("count", strconv.Itoa(count))
 	if cidType != "" {
-		params["type"] = string(cidType)
+		params.Set("type", string(cidType))
 	}
 
-	// 构建URL
-	url := "/v3/push/cid"
-	if len(params) > 0 {
-		var paramPairs []string
-		for k, v := range params {
-			paramPairs = append(paramPairs, fmt.Sprintf("%s=%s", k, v))
-		}
-		url += "?" + strings.Join(paramPairs, "&")
-	}
-
-	resp, err := s.client.makePushRequest(http.MethodGet, url, nil)
+	resp, err := s.client.makePushRequest(http.MethodGet, buildPath("/v3/push/cid", params), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var cidResp CIDResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &cidResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse CID response: %v", err))
+	if err := resp.Decode(&cidResp); err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse CID response", err)
 	}
 
 	return &cidResp, nil
@@ -140,9 +129,8 @@ func (s *AdvancedService) ValidatePush(req *PushRequest) (*PushResponse, error)
 	}
 
 	var pushResp PushResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &pushResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse push response: %v", err))
+	if err := resp.Decode(&pushResp); err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse push response", err)
 	}
 
 	return &pushResp, nil
@@ -155,8 +143,7 @@ func (s *AdvancedService) CancelPush(msgID string) error {
 		return NewJPushError(ErrorCodeInvalidParams, "message ID cannot be empty")
 	}
 
-	url := fmt.Sprintf("/v3/push/%s", msgID)
-	_, err := s.client.makePushRequest(http.MethodDelete, url, nil)
+	_, err := s.client.makePushRequest(http.MethodDelete, joinPath("/v3/push", msgID), nil)
 	return err
 }
 
@@ -168,9 +155,8 @@ func (s *AdvancedService) GetVendorQuota() (*QuotaResponse, error) {
 	}
 
 	var quotaResp QuotaResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &quotaResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse quota response: %v", err))
+	if err := resp.Decode(&quotaResp); err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse quota response", err)
 	}
 
 	return &quotaResp, nil
@@ -187,15 +173,19 @@ func (s *AdvancedService) PushByFile(req *FilePushRequest) (*PushResponse, error
 		return nil, err
 	}
 
+	// 校验接口不支持文件推送目标，试运行时只在本地校验
+	if s.client.dryRun != DryRunOff {
+		return s.client.dryRunPush(context.Background(), s.logger, "/v3/push/file", req, req.Notification, req.Message, false)
+	}
+
 	resp, err := s.client.makePushRequest(http.MethodPost, "/v3/push/file", req)
 	if err != nil {
 		return nil, err
 	}
 
 	var pushResp PushResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &pushResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse file push response: %v", err))
+	if err := resp.Decode(&pushResp); err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse file push response", err)
 	}
 
 	return &pushResp, nil
@@ -215,6 +205,12 @@ func (s *AdvancedService) validatePushRequest(req *PushRequest) error {
 		return NewJPushError(ErrorCodeInvalidParams, "at least one of notification or message is required")
 	}
 
+	if req.Callback != nil {
+		if err := req.Callback.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -236,6 +232,12 @@ func (s *AdvancedService) validateFilePushRequest(req *FilePushRequest) error {
 		return NewJPushError(ErrorCodeInvalidParams, "at least one of notification or message is required")
 	}
 
+	if req.Callback != nil {
+		if err := req.Callback.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
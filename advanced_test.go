@@ -384,4 +384,22 @@ func TestAdvancedService_NetworkTimeout(t *testing.T) {
 
 	_, err = client.Advanced.ValidatePush(request)
 	assert.Error(t, err)
-}
\ No newline at end of file
+}
+
+func TestAdvancedService_CancelPush_EscapesMsgID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/v3/push/a%2Fb%3Fc", r.URL.EscapedPath())
+		assert.Empty(t, r.URL.RawQuery)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	client.baseURLs["push"] = server.URL
+
+	err = client.Advanced.CancelPush("a/b?c")
+	assert.NoError(t, err)
+}
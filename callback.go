@@ -0,0 +1,294 @@
+package goserversdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CallbackEventType 回调事件类型
+type CallbackEventType int
+
+// 事件类型与CallbackType中的位一致
+const (
+	CallbackEventDelivered = CallbackEventType(CallbackTypeDelivered) // 送达回执
+	CallbackEventClicked   = CallbackEventType(CallbackTypeClicked)   // 点击回执
+	CallbackEventSent      = CallbackEventType(CallbackTypeSent)      // 推送成功回执
+)
+
+// String 返回事件类型名称
+func (t CallbackEventType) String() string {
+	switch t {
+	case CallbackEventDelivered:
+		return "delivered"
+	case CallbackEventClicked:
+		return "clicked"
+	case CallbackEventSent:
+		return "sent"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// callbackSignatureHeader 回调签名请求头，值为请求体HMAC-SHA256的十六进制编码
+const callbackSignatureHeader = "X-JPush-Signature"
+
+// maxCallbackBodySize 回调请求体的最大字节数
+const maxCallbackBodySize = 1 << 20
+
+// CallbackEvent JPush回调事件
+type CallbackEvent struct {
+	AppKey         string                 // 应用AppKey
+	MsgID          string                 // 消息ID
+	RegistrationID string                 // 设备注册ID
+	Platform       string                 // 平台，如android、ios
+	Type           CallbackEventType      // 事件类型
+	Channel        string                 // 下发通道，如jpush、huawei、xiaomi、apns
+	Time           time.Time              // 事件发生时间
+	Params         map[string]interface{} // 推送时Callback.Params中的自定义参数
+	Raw            json.RawMessage        // 原始事件JSON
+}
+
+// callbackEventJSON 回调事件的JSON结构
+type callbackEventJSON struct {
+	AppKey         string                 `json:"appkey"`
+	MsgID          flexibleID             `json:"msgid"`
+	RegistrationID string                 `json:"registration_id"`
+	Platform       string                 `json:"platform"`
+	Type           int                    `json:"type"`
+	Channel        string                 `json:"channel"`
+	Time           int64                  `json:"time"`
+	Params         map[string]interface{} `json:"params"`
+}
+
+// parseCallbackEvents 解析回调请求体，支持单个事件对象或事件数组
+func parseCallbackEvents(body []byte) ([]*CallbackEvent, error) {
+	body = bytes.TrimSpace(body)
+	var raws []json.RawMessage
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &raws); err != nil {
+			return nil, err
+		}
+	} else {
+		raws = []json.RawMessage{body}
+	}
+
+	events := make([]*CallbackEvent, 0, len(raws))
+	for _, raw := range raws {
+		var e callbackEventJSON
+		if err := decodeJSON(raw, &e); err != nil {
+			return nil, err
+		}
+		event := &CallbackEvent{
+			AppKey:         e.AppKey,
+			MsgID:          string(e.MsgID),
+			RegistrationID: e.RegistrationID,
+			Platform:       e.Platform,
+			Type:           CallbackEventType(e.Type),
+			Channel:        e.Channel,
+			Params:         e.Params,
+			Raw:            raw,
+		}
+		if e.Time > 0 {
+			event.Time = callbackTime(e.Time)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// callbackTime 将秒或毫秒时间戳转换为时间
+func callbackTime(ts int64) time.Time {
+	if ts > 1e12 {
+		return time.UnixMilli(ts)
+	}
+	return time.Unix(ts, 0)
+}
+
+// CallbackFunc 回调事件处理函数，返回错误时响应500以便JPush重试
+type CallbackFunc func(ctx context.Context, event *CallbackEvent) error
+
+// CallbackHandlerConfig 回调接收配置
+type CallbackHandlerConfig struct {
+	AppKey          string // 设置后拒绝AppKey不一致的事件
+	MasterSecret    string // 设置后校验Authorization: Basic base64(AppKey:MasterSecret)
+	SignatureSecret string // 设置后校验X-JPush-Signature请求头（请求体的HMAC-SHA256十六进制值）
+	Logger          Logger // 日志记录器，默认不输出日志
+}
+
+// CallbackHandler 接收JPush回调的http.Handler
+// 应在开始接收请求前通过On*方法注册处理函数
+type CallbackHandler struct {
+	config   CallbackHandlerConfig
+	logger   Logger
+	handlers map[CallbackEventType]CallbackFunc
+	fallback CallbackFunc
+}
+
+// NewCallbackHandler 创建回调接收处理器
+func NewCallbackHandler(config CallbackHandlerConfig) *CallbackHandler {
+	logger := config.Logger
+	if logger == nil {
+		logger = NopLogger()
+	}
+	return &CallbackHandler{
+		config:   config,
+		logger:   logger,
+		handlers: make(map[CallbackEventType]CallbackFunc),
+	}
+}
+
+// OnDelivered 注册送达回执处理函数
+func (h *CallbackHandler) OnDelivered(fn CallbackFunc) *CallbackHandler {
+	return h.On(CallbackEventDelivered, fn)
+}
+
+// OnClicked 注册点击回执处理函数
+func (h *CallbackHandler) OnClicked(fn CallbackFunc) *CallbackHandler {
+	return h.On(CallbackEventClicked, fn)
+}
+
+// OnSent 注册推送成功回执处理函数
+func (h *CallbackHandler) OnSent(fn CallbackFunc) *CallbackHandler {
+	return h.On(CallbackEventSent, fn)
+}
+
+// On 注册指定类型事件的处理函数
+func (h *CallbackHandler) On(eventType CallbackEventType, fn CallbackFunc) *CallbackHandler {
+	h.handlers[eventType] = fn
+	return h
+}
+
+// OnOther 注册未单独注册类型的事件处理函数
+func (h *CallbackHandler) OnOther(fn CallbackFunc) *CallbackHandler {
+	h.fallback = fn
+	return h
+}
+
+// ServeHTTP 实现http.Handler
+// GET请求用于回调地址校验，原样返回echostr参数；POST请求为回调事件
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.serveEcho(w, r)
+	case http.MethodPost:
+		h.serveEvents(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// serveEcho 响应回调地址校验
+func (h *CallbackHandler) serveEcho(w http.ResponseWriter, r *http.Request) {
+	echo := r.URL.Query().Get("echostr")
+	if echo == "" {
+		http.Error(w, "missing echostr", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, echo)
+}
+
+// serveEvents 校验并分发回调事件
+func (h *CallbackHandler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if !h.checkAuth(r) {
+		h.logger.Warn("回调认证失败", "remote_addr", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackBodySize+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxCallbackBodySize {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !h.checkSignature(r, body) {
+		h.logger.Warn("回调签名校验失败", "remote_addr", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	events, err := parseCallbackEvents(body)
+	if err != nil {
+		h.logger.Warn("回调请求体解析失败", "error", err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), events); err != nil {
+		h.logger.Error("回调事件处理失败", "error", err)
+		http.Error(w, "handler error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, `{"code":0,"message":"success"}`)
+}
+
+// dispatch 依次将事件分发给处理函数，跳过AppKey不匹配的事件
+func (h *CallbackHandler) dispatch(ctx context.Context, events []*CallbackEvent) error {
+	var errs []error
+	for _, event := range events {
+		if h.config.AppKey != "" && event.AppKey != "" && event.AppKey != h.config.AppKey {
+			h.logger.Warn("忽略AppKey不匹配的回调事件", "appkey", event.AppKey)
+			continue
+		}
+		fn, ok := h.handlers[event.Type]
+		if !ok {
+			fn = h.fallback
+		}
+		if fn == nil {
+			h.logger.Debug("未注册处理函数的回调事件", "type", event.Type.String())
+			continue
+		}
+		if err := fn(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("msg_id %s: %w", event.MsgID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkAuth 校验Basic认证
+func (h *CallbackHandler) checkAuth(r *http.Request) bool {
+	if h.config.MasterSecret == "" {
+		return true
+	}
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(h.config.AppKey+":"+h.config.MasterSecret))
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) == 1
+}
+
+// checkSignature 校验请求体签名
+func (h *CallbackHandler) checkSignature(r *http.Request, body []byte) bool {
+	if h.config.SignatureSecret == "" {
+		return true
+	}
+	got, err := hex.DecodeString(strings.TrimSpace(r.Header.Get(callbackSignatureHeader)))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, signCallbackBody(h.config.SignatureSecret, body))
+}
+
+// signCallbackBody 计算请求体的HMAC-SHA256签名
+func signCallbackBody(secret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
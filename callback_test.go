@@ -0,0 +1,154 @@
+package goserversdk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCallbackBody = `[
+	{"appkey":"app-key","msgid":18100000012345678,"registration_id":"1a0018970a8b2c3d","platform":"android",
+	 "type":1,"channel":"huawei","time":1700000000,"params":{"campaign":"spring"}},
+	{"appkey":"app-key","msgid":"18100000012345678","registration_id":"1a0018970a8b2c3d","platform":"android",
+	 "type":2,"time":1700000060000},
+	{"appkey":"app-key","msgid":"18100000012345678","registration_id":"1a0018970a8b2c3d","platform":"android",
+	 "type":8,"channel":"xiaomi"}
+]`
+
+func newCallbackRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/jpush/callback", strings.NewReader(body))
+}
+
+func TestParseCallbackEvents(t *testing.T) {
+	events, err := parseCallbackEvents([]byte(testCallbackBody))
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	assert.Equal(t, CallbackEventDelivered, events[0].Type)
+	assert.Equal(t, "18100000012345678", events[0].MsgID)
+	assert.Equal(t, "huawei", events[0].Channel)
+	assert.Equal(t, time.Unix(1700000000, 0), events[0].Time)
+	assert.Equal(t, "spring", events[0].Params["campaign"])
+
+	assert.Equal(t, time.UnixMilli(1700000060000), events[1].Time)
+	assert.Equal(t, CallbackEventSent, events[2].Type)
+
+	// 单个对象
+	events, err = parseCallbackEvents([]byte(`{"msgid":"1","type":2}`))
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "clicked", events[0].Type.String())
+
+	_, err = parseCallbackEvents([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestCallbackHandler_Dispatch(t *testing.T) {
+	var delivered, clicked, other []*CallbackEvent
+	h := NewCallbackHandler(CallbackHandlerConfig{AppKey: "app-key"}).
+		OnDelivered(func(ctx context.Context, e *CallbackEvent) error {
+			delivered = append(delivered, e)
+			return nil
+		}).
+		OnClicked(func(ctx context.Context, e *CallbackEvent) error {
+			clicked = append(clicked, e)
+			return nil
+		}).
+		OnOther(func(ctx context.Context, e *CallbackEvent) error {
+			other = append(other, e)
+			return nil
+		})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newCallbackRequest(testCallbackBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"code":0,"message":"success"}`, rec.Body.String())
+	assert.Len(t, delivered, 1)
+	assert.Len(t, clicked, 1)
+	assert.Len(t, other, 1)
+	assert.Equal(t, CallbackEventSent, other[0].Type)
+
+	// AppKey不匹配的事件被忽略
+	delivered = nil
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, newCallbackRequest(`{"appkey":"other","msgid":"1","type":1}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, delivered)
+}
+
+func TestCallbackHandler_HandlerError(t *testing.T) {
+	h := NewCallbackHandler(CallbackHandlerConfig{}).
+		OnDelivered(func(ctx context.Context, e *CallbackEvent) error {
+			return errors.New("db unavailable")
+		})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newCallbackRequest(`{"msgid":"1","type":1}`))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, newCallbackRequest(`{bad`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCallbackHandler_Echo(t *testing.T) {
+	h := NewCallbackHandler(CallbackHandlerConfig{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jpush/callback?echostr=abc123", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "abc123", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jpush/callback", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/jpush/callback", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestCallbackHandler_Auth(t *testing.T) {
+	var called int
+	h := NewCallbackHandler(CallbackHandlerConfig{
+		AppKey:          "app-key",
+		MasterSecret:    "master-secret",
+		SignatureSecret: "sign-secret",
+	}).OnDelivered(func(ctx context.Context, e *CallbackEvent) error {
+		called++
+		return nil
+	})
+
+	body := `{"appkey":"app-key","msgid":"1","type":1}`
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("app-key:master-secret"))
+	signature := hex.EncodeToString(signCallbackBody("sign-secret", []byte(body)))
+
+	// 缺少认证头
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newCallbackRequest(body))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// 签名错误
+	req := newCallbackRequest(body)
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("X-JPush-Signature", hex.EncodeToString(signCallbackBody("wrong", []byte(body))))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = newCallbackRequest(body)
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("X-JPush-Signature", signature)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, called)
+}
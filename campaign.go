@@ -0,0 +1,493 @@
+package goserversdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Campaign 以文件声明的推送活动
+//
+// 文件顶层为PushRequest的字段（字段名与JSON请求一致），另外可以包含name、description：
+//
+//	name: spring-sale
+//	platform: [android, ios]
+//	audience:
+//	  tag: [vip]
+//	notification:
+//	  alert: 春季大促
+//	  android:
+//	    title: 限时优惠
+//	options:
+//	  time_to_live: 86400
+type Campaign struct {
+	Name        string       // 活动名称
+	Description string       // 活动说明
+	Request     *PushRequest // 推送请求
+}
+
+// CampaignError 推送活动文件中的单个错误
+type CampaignError struct {
+	File    string // 文件名
+	Line    int    // 行号，从1开始
+	Column  int    // 列号，从1开始，未知时为0
+	Path    string // 出错的字段路径，如notification.android.title
+	Message string // 错误说明
+}
+
+// Error 实现error接口，格式为file:line:column: path: message
+func (e *CampaignError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ":%d", e.Column)
+		}
+	}
+	b.WriteString(": ")
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// CampaignErrors 推送活动文件中的全部错误，按出现顺序排列
+type CampaignErrors []*CampaignError
+
+// Error 实现error接口，每行一个错误
+func (errs CampaignErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// campaignPlatforms 推送活动文件中允许的平台
+var campaignPlatforms = map[string]bool{
+	PlatformAndroid:  true,
+	PlatformIOS:      true,
+	PlatformHMOS:     true,
+	PlatformQuickApp: true,
+}
+
+// LoadCampaignFile 读取并校验YAML或JSON格式的推送活动文件
+// 校验失败时返回的JPushError包装了CampaignErrors，可通过errors.As获取带行号的错误列表
+func LoadCampaignFile(path string) (*Campaign, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidParams, "读取推送活动文件失败", err)
+	}
+	return ParseCampaign(filepath.Base(path), data)
+}
+
+// ParseCampaign 解析并校验推送活动，name用于错误信息中的文件名
+// 内容以{开头时按JSON解析，否则按YAML解析
+func ParseCampaign(name string, data []byte) (*Campaign, error) {
+	root, errs := parseCampaignNode(name, data)
+	if len(errs) > 0 {
+		return nil, wrapJPushError(ErrorCodeInvalidParams, "推送活动文件校验失败", errs)
+	}
+
+	d := &campaignDecoder{file: name, keys: make(map[string]*yaml.Node)}
+	campaign := d.decodeCampaign(root)
+	if len(d.errs) > 0 {
+		return nil, wrapJPushError(ErrorCodeInvalidParams, "推送活动文件校验失败", d.errs)
+	}
+	return campaign, nil
+}
+
+// yamlLinePattern yaml错误信息中的行号
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// parseCampaignNode 将文件内容解析为YAML节点，JSON按YAML的子集解析以获得行号
+func parseCampaignNode(name string, data []byte) (*yaml.Node, CampaignErrors) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, CampaignErrors{{File: name, Line: 1, Message: "文件为空"}}
+	}
+
+	if trimmed[0] == '{' {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			e := &CampaignError{File: name, Message: "JSON格式错误: " + err.Error()}
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				e.Line, e.Column = offsetPosition(data, syntaxErr.Offset)
+			}
+			return nil, CampaignErrors{e}
+		}
+		// 合法JSON中的制表符只能是空白，替换为空格以免被YAML拒绝，行列号不变
+		data = bytes.ReplaceAll(data, []byte("\t"), []byte(" "))
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		e := &CampaignError{File: name, Message: "YAML格式错误: " + strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			e.Line, _ = strconv.Atoi(m[1])
+		}
+		return nil, CampaignErrors{e}
+	}
+	if len(doc.Content) == 0 {
+		return nil, CampaignErrors{{File: name, Line: 1, Message: "文件为空"}}
+	}
+	return doc.Content[0], nil
+}
+
+// offsetPosition 将字节偏移转换为行列号
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// campaignDecoder 按PushRequest的结构逐字段解析YAML节点并收集错误
+type campaignDecoder struct {
+	file string
+	errs CampaignErrors
+	root *yaml.Node
+	keys map[string]*yaml.Node // 顶层字段的键节点，用于定位语义校验错误
+}
+
+// errorf 记录节点位置的错误
+func (d *campaignDecoder) errorf(n *yaml.Node, path, format string, args ...interface{}) {
+	d.errs = append(d.errs, &CampaignError{
+		File:    d.file,
+		Line:    n.Line,
+		Column:  n.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// decodeCampaign 解析顶层节点
+func (d *campaignDecoder) decodeCampaign(root *yaml.Node) *Campaign {
+	d.root = root
+	root = resolveAlias(root)
+	if root.Kind != yaml.MappingNode {
+		d.errorf(root, "", "顶层应为对象")
+		return nil
+	}
+
+	campaign := &Campaign{}
+	fields := jsonFields(reflect.TypeOf(PushRequest{}))
+	values := make(map[string]interface{})
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], resolveAlias(root.Content[i+1])
+		key := keyNode.Value
+		if _, dup := d.keys[key]; dup {
+			d.errorf(keyNode, key, "字段重复")
+			continue
+		}
+		d.keys[key] = keyNode
+
+		switch key {
+		case "name":
+			campaign.Name = d.scalarString(valueNode, key)
+		case "description":
+			campaign.Description = d.scalarString(valueNode, key)
+		case "platform":
+			if v := d.decodePlatform(valueNode); v != nil {
+				values[key] = v
+			}
+		case "audience":
+			if v := d.decodeAudience(valueNode); v != nil {
+				values[key] = v
+			}
+		default:
+			field, ok := fields[key]
+			if !ok {
+				d.errorf(keyNode, key, "未知字段")
+				continue
+			}
+			if v := d.decode(valueNode, field.Type, key); v != nil {
+				values[key] = v
+			}
+		}
+	}
+	if len(d.errs) > 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(values)
+	if err == nil {
+		campaign.Request = &PushRequest{}
+		err = json.Unmarshal(data, campaign.Request)
+	}
+	if err != nil {
+		d.errorf(root, "", "无法转换为推送请求: %v", err)
+		return nil
+	}
+
+	d.validate(campaign.Request)
+	if len(d.errs) > 0 {
+		return nil
+	}
+	return campaign
+}
+
+// validate 使用SDK的推送校验规则校验请求，并将错误定位到对应的顶层字段
+func (d *campaignDecoder) validate(req *PushRequest) {
+	err := (&PushService{}).validatePushRequest(req)
+	if err == nil {
+		err = validatePushPayloadSize(req.Notification, req.Message)
+		if err != nil {
+			d.fieldError(err, "notification", "message")
+		}
+		return
+	}
+
+	switch GetErrorCode(err) {
+	case ErrorCodeInvalidPlatform:
+		d.fieldError(err, "platform")
+	case ErrorCodeInvalidAudience:
+		d.fieldError(err, "audience")
+	case ErrorCodeInvalidNotification:
+		d.fieldError(err, "notification")
+	case ErrorCodeInvalidMessage:
+		d.fieldError(err, "message")
+	default:
+		if req.Callback != nil && req.Callback.Validate() != nil {
+			d.fieldError(err, "callback")
+		} else {
+			d.fieldError(err, "notification", "message")
+		}
+	}
+}
+
+// fieldError 在第一个存在的顶层字段处记录校验错误，字段都不存在时定位到文件开头
+func (d *campaignDecoder) fieldError(err error, keys ...string) {
+	message := err.Error()
+	var jerr *JPushError
+	if errors.As(err, &jerr) {
+		message = jerr.Message
+	}
+	for _, key := range keys {
+		if n, ok := d.keys[key]; ok {
+			d.errorf(n, key, "%s", message)
+			return
+		}
+	}
+	d.errorf(d.root, "", "%s", message)
+}
+
+// decodePlatform 解析platform：字符串all或平台列表
+func (d *campaignDecoder) decodePlatform(n *yaml.Node) interface{} {
+	if n.Kind == yaml.ScalarNode {
+		if n.Value != PlatformAll {
+			d.errorf(n, "platform", "应为all或平台列表，实际为%q", n.Value)
+			return nil
+		}
+		return PlatformAll
+	}
+	if n.Kind != yaml.SequenceNode || len(n.Content) == 0 {
+		d.errorf(n, "platform", "应为all或平台列表")
+		return nil
+	}
+	platforms := make([]string, 0, len(n.Content))
+	for i, item := range n.Content {
+		item = resolveAlias(item)
+		path := fmt.Sprintf("platform[%d]", i)
+		if item.Kind != yaml.ScalarNode || !campaignPlatforms[item.Value] {
+			d.errorf(item, path, "不支持的平台%q，可选android、ios、hmos、quickapp", item.Value)
+			continue
+		}
+		platforms = append(platforms, item.Value)
+	}
+	return platforms
+}
+
+// decodeAudience 解析audience：字符串all或推送目标对象
+func (d *campaignDecoder) decodeAudience(n *yaml.Node) interface{} {
+	if n.Kind == yaml.ScalarNode && n.Tag != "!!null" {
+		if n.Value != "all" {
+			d.errorf(n, "audience", "应为all或推送目标对象，实际为%q", n.Value)
+			return nil
+		}
+		return "all"
+	}
+	return d.decode(n, reflect.TypeOf(Audience{}), "audience")
+}
+
+// scalarString 解析字符串标量
+func (d *campaignDecoder) scalarString(n *yaml.Node, path string) string {
+	if v, ok := d.decode(n, reflect.TypeOf(""), path).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// decode 按目标类型解析节点，返回可JSON序列化的值；null或出错时返回nil
+func (d *campaignDecoder) decode(n *yaml.Node, t reflect.Type, path string) interface{} {
+	n = resolveAlias(n)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			d.errorf(n, path, "%v", err)
+			return nil
+		}
+		return normalizeYAML(v)
+
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			d.errorf(n, path, "应为对象")
+			return nil
+		}
+		fields := jsonFields(t)
+		out := make(map[string]interface{})
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			keyNode := n.Content[i]
+			childPath := path + "." + keyNode.Value
+			if seen[keyNode.Value] {
+				d.errorf(keyNode, childPath, "字段重复")
+				continue
+			}
+			seen[keyNode.Value] = true
+			field, ok := fields[keyNode.Value]
+			if !ok {
+				d.errorf(keyNode, childPath, "未知字段")
+				continue
+			}
+			if v := d.decode(n.Content[i+1], field.Type, childPath); v != nil {
+				out[keyNode.Value] = v
+			}
+		}
+		return out
+
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			d.errorf(n, path, "应为对象")
+			return nil
+		}
+		out := make(map[string]interface{})
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if v := d.decode(n.Content[i+1], t.Elem(), path+"."+key); v != nil {
+				out[key] = v
+			}
+		}
+		return out
+
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			d.errorf(n, path, "应为列表")
+			return nil
+		}
+		out := make([]interface{}, 0, len(n.Content))
+		for i, item := range n.Content {
+			if v := d.decode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); v != nil {
+				out = append(out, v)
+			}
+		}
+		return out
+
+	case reflect.String:
+		if n.Kind != yaml.ScalarNode {
+			d.errorf(n, path, "应为字符串")
+			return nil
+		}
+		return n.Value
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!int" || n.Decode(&v) != nil {
+			d.errorf(n, path, "应为整数，实际为%q", n.Value)
+			return nil
+		}
+		return v
+
+	case reflect.Float32, reflect.Float64:
+		var v float64
+		if n.Kind != yaml.ScalarNode || (n.Tag != "!!int" && n.Tag != "!!float") || n.Decode(&v) != nil {
+			d.errorf(n, path, "应为数字，实际为%q", n.Value)
+			return nil
+		}
+		return v
+
+	case reflect.Bool:
+		var v bool
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!bool" || n.Decode(&v) != nil {
+			d.errorf(n, path, "应为true或false，实际为%q", n.Value)
+			return nil
+		}
+		return v
+	}
+
+	d.errorf(n, path, "不支持的字段类型%s", t)
+	return nil
+}
+
+// jsonFields 返回结构体按JSON字段名索引的字段
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// resolveAlias 解析YAML别名节点
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// normalizeYAML 将YAML解码结果中的map[interface{}]interface{}转换为可JSON序列化的map[string]interface{}
+func normalizeYAML(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = normalizeYAML(child)
+		}
+		return val
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			out[fmt.Sprint(k)] = normalizeYAML(child)
+		}
+		return out
+	case []interface{}:
+		for i, child := range val {
+			val[i] = normalizeYAML(child)
+		}
+		return val
+	default:
+		return v
+	}
+}
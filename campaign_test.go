@@ -0,0 +1,151 @@
+package goserversdk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCampaignYAML = `# 春季活动
+name: spring-sale
+description: 春季大促提醒
+platform: [android, ios]
+audience:
+  tag: [vip, beijing]
+  tag_not: [unsubscribed]
+notification:
+  alert: 春季大促开始啦
+  android:
+    alert: 春季大促开始啦
+    title: 限时优惠
+    builder_id: 1
+    extras: &extras
+      campaign: spring
+  ios:
+    alert:
+      title: 限时优惠
+      body: 春季大促开始啦
+    badge: "+1"
+    extras: *extras
+options:
+  time_to_live: 86400
+  apns_production: true
+`
+
+// campaignErrors 从ParseCampaign的错误中取出CampaignErrors
+func campaignErrors(t *testing.T, err error) CampaignErrors {
+	t.Helper()
+	var errs CampaignErrors
+	if !assert.True(t, errors.As(err, &errs), "unexpected error: %v", err) {
+		return nil
+	}
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+	return errs
+}
+
+func TestParseCampaign_YAML(t *testing.T) {
+	campaign, err := ParseCampaign("spring.yaml", []byte(testCampaignYAML))
+	assert.NoError(t, err)
+	assert.Equal(t, "spring-sale", campaign.Name)
+	assert.Equal(t, "春季大促提醒", campaign.Description)
+
+	req := campaign.Request
+	assert.Equal(t, []interface{}{"android", "ios"}, req.Platform)
+	assert.Equal(t, []string{"vip", "beijing"}, req.Audience.Tag)
+	assert.Equal(t, []string{"unsubscribed"}, req.Audience.TagNot)
+	assert.Equal(t, "限时优惠", *req.Notification.Android.Title)
+	assert.Equal(t, 1, *req.Notification.Android.BuilderID)
+	assert.Equal(t, "spring", req.Notification.IOS.Extras["campaign"])
+	assert.Equal(t, "春季大促开始啦", req.Notification.IOS.Alert.(map[string]interface{})["body"])
+	assert.Equal(t, "+1", req.Notification.IOS.Badge)
+	assert.Equal(t, 86400, *req.Options.TimeToLive)
+	assert.True(t, *req.Options.APNSProduction)
+}
+
+func TestParseCampaign_JSON(t *testing.T) {
+	data := "{\n\t\"platform\": \"all\",\n\t\"audience\": \"all\",\n\t\"message\": {\"msg_content\": \"hello\", \"extras\": {\"n\": 1}}\n}"
+	campaign, err := ParseCampaign("broadcast.json", []byte(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "all", campaign.Request.Platform)
+	assert.NotNil(t, campaign.Request.Audience.All)
+	assert.Equal(t, "hello", campaign.Request.Message.MsgContent)
+
+	// JSON语法错误带行列号
+	_, err = ParseCampaign("bad.json", []byte("{\n  \"platform\": \"all\",\n  \"audience\" \"all\"\n}"))
+	errs := campaignErrors(t, err)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, 3, errs[0].Line)
+		assert.Contains(t, errs[0].Error(), "bad.json:3:")
+	}
+}
+
+func TestParseCampaign_FieldErrors(t *testing.T) {
+	data := `platform: [android, windows]
+audience:
+  tags: [vip]
+notification:
+  android:
+    titel: typo
+    builder_id: one
+options:
+  apns_production: maybe
+`
+	_, err := ParseCampaign("broken.yaml", []byte(data))
+	errs := campaignErrors(t, err)
+	if !assert.Len(t, errs, 5) {
+		return
+	}
+	assert.Equal(t, "broken.yaml:1:21: platform[1]: 不支持的平台\"windows\"，可选android、ios、hmos、quickapp", errs[0].Error())
+	assert.Equal(t, 3, errs[1].Line)
+	assert.Equal(t, "audience.tags", errs[1].Path)
+	assert.Equal(t, "未知字段", errs[1].Message)
+	assert.Equal(t, "notification.android.titel", errs[2].Path)
+	assert.Equal(t, 6, errs[2].Line)
+	assert.Equal(t, "notification.android.builder_id", errs[3].Path)
+	assert.Equal(t, 7, errs[3].Line)
+	assert.Equal(t, 9, errs[4].Line)
+}
+
+func TestParseCampaign_ValidatorErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		line int
+		path string
+	}{
+		{"缺少内容", "platform: all\naudience: all\n", 1, ""},
+		{"空推送目标", "platform: all\naudience: {}\nmessage:\n  msg_content: hi\n", 2, "audience"},
+		{"空通知", "platform: all\naudience: all\nnotification: {}\n", 3, "notification"},
+		{"回调地址", "platform: all\naudience: all\nmessage:\n  msg_content: hi\ncallback:\n  url: http://example.com\n", 5, "callback"},
+		{"消息体过大", "platform: all\naudience: all\n\nnotification:\n  alert: " + strings.Repeat("a", maxPushPayloadSize) + "\n", 4, "notification"},
+		{"YAML语法", "platform: all\n audience: all\n", 2, ""},
+		{"重复字段", "platform: all\nplatform: all\n", 2, "platform"},
+		{"空文件", "  \n", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCampaign("c.yaml", []byte(tt.data))
+			errs := campaignErrors(t, err)
+			if assert.NotEmpty(t, errs) {
+				assert.Equal(t, tt.line, errs[0].Line, errs.Error())
+				assert.Equal(t, tt.path, errs[0].Path)
+			}
+		})
+	}
+}
+
+func TestLoadCampaignFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spring.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(testCampaignYAML), 0o644))
+
+	campaign, err := LoadCampaignFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "spring-sale", campaign.Name)
+
+	_, err = LoadCampaignFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+}
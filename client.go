@@ -3,12 +3,16 @@ package goserversdk
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 )
@@ -17,9 +21,16 @@ import (
 type Client struct {
 	appKey       string
 	masterSecret string
-	logger       *zap.Logger
+	logger       Logger
 	httpClient   *http.Client
 	baseURLs     map[string]string
+	userAgent    string
+	redactor     *redactor
+	middlewares  []Middleware
+	handler      RequestHandler
+	tracer       Tracer
+	dryRun       DryRunMode
+	dryRunSeq    int64
 	Push         *PushService
 	Advanced     *AdvancedService
 	Report       *ReportService
@@ -29,8 +40,64 @@ type Client struct {
 type Config struct {
 	AppKey       string        // JPush应用的AppKey
 	MasterSecret string        // JPush应用的MasterSecret
-	Logger       *zap.Logger   // 日志记录器
+	Logger       *zap.Logger   // zap日志记录器，可选
 	Timeout      time.Duration // HTTP请求超时时间，默认30秒
+
+	HTTPClient  *http.Client                          // 自定义HTTP客户端，设置后忽略Timeout、Transport、Proxy和TLSConfig
+	Transport   http.RoundTripper                     // 自定义传输层，设置后忽略Proxy和TLSConfig
+	Proxy       func(*http.Request) (*url.URL, error) // 代理选择函数，如http.ProxyURL(u)，默认读取环境变量
+	TLSConfig   *tls.Config                           // TLS配置
+	UserAgent   string                                // User-Agent请求头，默认jpush-go-sdk
+	LogAdapter  Logger                                // 日志接口，如NewSlogLogger(l)，优先于Logger；两者均未设置时不输出日志
+	LogLevel    LogLevel                              // SDK输出的最低日志级别，默认不过滤
+	LogLevels   map[string]LogLevel                   // 按作用域（LogScopeHTTP、LogScopePush等）设置的日志级别，优先于LogLevel
+	Redaction   RedactionPolicy                       // 日志脱敏策略，默认RedactionMasked
+	RedactKeys  []string                              // 额外需要脱敏的JSON字段，如extras中的自定义字段
+	Middlewares []Middleware                          // 请求中间件，按顺序由外到内执行，也可通过Client.Use追加
+	Tracer      Tracer                                // 链路追踪接口，设置后为服务调用和HTTP请求生成span，并注入traceparent请求头
+	Metrics     Metrics                               // 指标接口，如NewPrometheusMetrics，设置后在Middlewares之后自动注册MetricsMiddleware
+	Region      Region                                // 数据中心区域，默认中国大陆
+	BaseURLs    *BaseURLs                             // 各服务的域名，未设置的字段使用Region对应的默认值
+	Recorder    *Recorder                             // 录制回放传输层，设置后包裹HTTPClient或Transport，用于基于夹具文件的测试
+	DryRun      DryRunMode                            // 试运行模式，开启后推送不会实际下发，返回DryRun为true的响应
+}
+
+// Region 数据中心区域
+type Region string
+
+const (
+	RegionMainland Region = "cn" // 中国大陆数据中心（默认）
+	RegionHK       Region = "hk" // 香港数据中心，适用于在境外注册的应用
+)
+
+// BaseURLs 各服务的域名
+type BaseURLs struct {
+	Push     string // 推送服务，默认https://api.jpush.cn
+	Device   string // 设备服务，默认https://device.jpush.cn
+	Report   string // 统计服务，默认https://report.jpush.cn
+	Schedule string // 定时任务服务，默认https://api.jpush.cn
+	Admin    string // 应用管理服务，默认https://admin.jpush.cn
+}
+
+// defaultUserAgent 默认User-Agent请求头
+const defaultUserAgent = "jpush-go-sdk"
+
+// regionBaseURLs 各区域下各服务的默认域名
+var regionBaseURLs = map[Region]BaseURLs{
+	RegionMainland: {
+		Push:     "https://api.jpush.cn",
+		Device:   "https://device.jpush.cn",
+		Report:   "https://report.jpush.cn",
+		Schedule: "https://api.jpush.cn",
+		Admin:    "https://admin.jpush.cn",
+	},
+	RegionHK: {
+		Push:     "https://api.hk.jpush.cn",
+		Device:   "https://device.hk.jpush.cn",
+		Report:   "https://report.hk.jpush.cn",
+		Schedule: "https://api.hk.jpush.cn",
+		Admin:    "https://admin.hk.jpush.cn",
+	},
 }
 
 // NewClient 创建JPush客户端
@@ -41,30 +108,44 @@ func NewClient(config *Config) (*Client, error) {
 	if config.MasterSecret == "" {
 		return nil, NewJPushError(ErrorCodeMissingAuth, "MasterSecret不能为空")
 	}
-	if config.Logger == nil {
-		return nil, NewJPushError(ErrorCodeInvalidParams, "Logger不能为空")
+
+	baseURLs, err := resolveBaseURLs(config.Region, config.BaseURLs)
+	if err != nil {
+		return nil, err
 	}
 
-	timeout := config.Timeout
-	if timeout == 0 {
-		timeout = 30 * time.Second
+	if config.DryRun < DryRunOff || config.DryRun > DryRunLocal {
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("不支持的试运行模式: %s", config.DryRun))
+	}
+
+	logger := resolveLogger(config)
+
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
 	}
 
 	client := &Client{
 		appKey:       config.AppKey,
 		masterSecret: config.MasterSecret,
-		logger:       config.Logger,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		baseURLs: map[string]string{
-			"push":   "https://api.jpush.cn",
-			"device": "https://device.jpush.cn",
-			"report": "https://report.jpush.cn",
-		},
-		Push:     &PushService{},
-		Advanced: &AdvancedService{},
-		Report:   &ReportService{},
+		logger:       scopedLogger(logger, config, LogScopeHTTP),
+		httpClient:   newHTTPClient(config),
+		baseURLs:     baseURLs,
+		userAgent:    userAgent,
+		redactor:     newRedactor(config.Redaction, config.RedactKeys),
+		dryRun:       config.DryRun,
+		Push:         &PushService{logger: scopedLogger(logger, config, LogScopePush)},
+		Advanced:     &AdvancedService{logger: scopedLogger(logger, config, LogScopeAdvanced)},
+		Report:       &ReportService{logger: scopedLogger(logger, config, LogScopeReport)},
+	}
+
+	client.Use(config.Middlewares...)
+	if config.Tracer != nil {
+		client.tracer = config.Tracer
+		client.Use(TracingMiddleware(config.Tracer))
+	}
+	if config.Metrics != nil {
+		client.Use(MetricsMiddleware(config.Metrics))
 	}
 
 	// 初始化服务
@@ -75,32 +156,187 @@ func NewClient(config *Config) (*Client, error) {
 	return client, nil
 }
 
+// newHTTPClient 根据配置创建HTTP客户端
+func newHTTPClient(config *Config) *http.Client {
+	if config.HTTPClient != nil {
+		if config.Recorder == nil {
+			return config.HTTPClient
+		}
+		hc := *config.HTTPClient
+		hc.Transport = config.Recorder.wrap(hc.Transport)
+		return &hc
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	transport := config.Transport
+	if transport == nil && (config.Proxy != nil || config.TLSConfig != nil) {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if config.Proxy != nil {
+			t.Proxy = config.Proxy
+		}
+		if config.TLSConfig != nil {
+			t.TLSClientConfig = config.TLSConfig
+		}
+		transport = t
+	}
+	if config.Recorder != nil {
+		transport = config.Recorder.wrap(transport)
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// resolveBaseURLs 合并自定义域名与区域默认域名，并校验域名格式
+func resolveBaseURLs(region Region, overrides *BaseURLs) (map[string]string, error) {
+	if region == "" {
+		region = RegionMainland
+	}
+	urls, ok := regionBaseURLs[region]
+	if !ok {
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("不支持的区域: %q", region))
+	}
+	if overrides != nil {
+		if overrides.Push != "" {
+			urls.Push = overrides.Push
+		}
+		if overrides.Device != "" {
+			urls.Device = overrides.Device
+		}
+		if overrides.Report != "" {
+			urls.Report = overrides.Report
+		}
+		if overrides.Schedule != "" {
+			urls.Schedule = overrides.Schedule
+		}
+		if overrides.Admin != "" {
+			urls.Admin = overrides.Admin
+		}
+	}
+
+	resolved := map[string]string{
+		"push":     urls.Push,
+		"device":   urls.Device,
+		"report":   urls.Report,
+		"schedule": urls.Schedule,
+		"admin":    urls.Admin,
+	}
+	for family, raw := range resolved {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("%s服务域名无效: %q", family, raw))
+		}
+		resolved[family] = strings.TrimSuffix(raw, "/")
+	}
+	return resolved, nil
+}
+
 // APIResponse API响应结构
 type APIResponse struct {
-	StatusCode int                    `json:"-"`
-	Headers    map[string][]string    `json:"-"`
-	Body       map[string]interface{} `json:"-"`
-	Error      *JPushError            `json:"error,omitempty"`
+	StatusCode int                 `json:"-"`
+	Headers    map[string][]string `json:"-"`
+	Body       []byte              `json:"-"` // 原始响应体
+	Error      *JPushError         `json:"error,omitempty"`
+}
+
+// errorEnvelope 响应体中的错误信息
+type errorEnvelope struct {
+	Error *struct {
+		Code    *json.Number `json:"code"`
+		Message *string      `json:"message"`
+	} `json:"error"`
+}
+
+// Decode 将原始响应体直接解码到v
+// 数字解码到interface{}时保留为json.Number，避免大整数丢失精度；响应体为空时不修改v
+func (r *APIResponse) Decode(v interface{}) error {
+	if r == nil || len(bytes.TrimSpace(r.Body)) == 0 {
+		return nil
+	}
+	return decodeJSON(r.Body, v)
+}
+
+// decodeJSON 使用json.Number解码数字
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// decodeResponseList 解码数组响应
+// 服务端对单条结果可能直接返回对象，此时作为只有一个元素的数组处理；响应体为空时返回空数组
+func decodeResponseList[T any](resp *APIResponse) ([]T, error) {
+	data := bytes.TrimSpace(resp.Body)
+	if len(data) == 0 {
+		return []T{}, nil
+	}
+
+	if data[0] == '{' {
+		var item T
+		if err := decodeJSON(data, &item); err != nil {
+			return nil, err
+		}
+		return []T{item}, nil
+	}
+
+	var list []T
+	if err := decodeJSON(data, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// parseErrorEnvelope 从对象类型的响应体中提取错误信息，没有错误信息时返回nil
+func parseErrorEnvelope(data []byte) *JPushError {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil
+	}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Error == nil {
+		return nil
+	}
+
+	code := ErrorCodeInternalError
+	message := "未知错误"
+	if envelope.Error.Code != nil {
+		if codeVal, err := envelope.Error.Code.Int64(); err == nil {
+			code = ErrorCode(codeVal)
+		}
+	}
+	if envelope.Error.Message != nil {
+		message = *envelope.Error.Message
+	}
+
+	return NewJPushError(code, message)
 }
 
 // makeRequest 发送HTTP请求
 func (c *Client) makeRequest(ctx context.Context, method, baseURL, path string, body interface{}) (*APIResponse, error) {
-	var reqBody io.Reader
+	req := &Request{
+		Method:  method,
+		BaseURL: baseURL,
+		Path:    path,
+		Header:  make(http.Header),
+	}
+
 	if body != nil {
 		jsonData, err := json.Marshal(body)
 		if err != nil {
-			c.logger.Error("序列化请求体失败", zap.Error(err))
-			return nil, NewJPushError(ErrorCodeInvalidJSON, "请求体序列化失败")
+			c.logger.Error("序列化请求体失败", "error", err)
+			return nil, wrapJPushError(ErrorCodeInvalidJSON, "请求体序列化失败", err)
+		}
+		req.Body = jsonData
+		if logEnabled(c.logger, LogLevelDebug) {
+			c.logger.Debug("请求体", "body", c.redactor.body(jsonData))
 		}
-		reqBody = bytes.NewBuffer(jsonData)
-		c.logger.Debug("请求体", zap.String("body", string(jsonData)))
-	}
-
-	url := baseURL + path
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
-	if err != nil {
-		c.logger.Error("创建HTTP请求失败", zap.Error(err))
-		return nil, NewJPushError(ErrorCodeInternalError, "创建HTTP请求失败")
 	}
 
 	// 设置认证头
@@ -108,85 +344,83 @@ func (c *Client) makeRequest(ctx context.Context, method, baseURL, path string,
 	req.Header.Set("Authorization", "Basic "+auth)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	return c.handler(ctx, req)
+}
+
+// doRequest 发送HTTP请求并解析响应，位于中间件链的最内层
+func (c *Client) doRequest(ctx context.Context, r *Request) (*APIResponse, error) {
+	path := r.Path
+
+	var reqBody io.Reader
+	if r.Body != nil {
+		reqBody = bytes.NewReader(r.Body)
+	}
 
-	c.logger.Debug("发送HTTP请求",
-		zap.String("method", method),
-		zap.String("url", url),
-		zap.Any("headers", req.Header))
+	url := r.BaseURL + path
+	req, err := http.NewRequestWithContext(ctx, r.Method, url, reqBody)
+	if err != nil {
+		c.logger.Error("创建HTTP请求失败", "error", err)
+		return nil, withRequestPath(wrapJPushError(ErrorCodeInvalidRequest, "创建HTTP请求失败", err), path)
+	}
+	req.Header = r.Header.Clone()
+
+	if logEnabled(c.logger, LogLevelDebug) {
+		c.logger.Debug("发送HTTP请求",
+			"method", r.Method,
+			"url", url,
+			"headers", c.redactor.headers(req.Header))
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		c.logger.Error("HTTP请求失败", zap.Error(err))
-		return nil, NewJPushError(ErrorCodeTimeout, "HTTP请求失败")
+		code, message := transportErrorCode(err)
+		c.logger.Error("HTTP请求失败", "code", int(code), "error", err)
+		return nil, withRequestPath(wrapJPushError(code, message, err), path)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.logger.Error("读取响应体失败", zap.Error(err))
-		return nil, NewJPushError(ErrorCodeInternalError, "读取响应体失败")
+		c.logger.Error("读取响应体失败", "error", err)
+		code, _ := transportErrorCode(err)
+		jpushErr := wrapJPushError(code, "读取响应体失败", err)
+		return nil, withResponseInfo(jpushErr, path, resp.StatusCode, resp.Header, nil)
 	}
 
-	c.logger.Debug("收到HTTP响应",
-		zap.Int("status_code", resp.StatusCode),
-		zap.Any("headers", resp.Header),
-		zap.String("body", string(respBody)))
+	if logEnabled(c.logger, LogLevelDebug) {
+		c.logger.Debug("收到HTTP响应",
+			"status_code", resp.StatusCode,
+			"headers", c.redactor.headers(resp.Header),
+			"body", c.redactor.body(respBody))
+	}
 
 	apiResp := &APIResponse{
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header,
 	}
 
-	// 解析响应体
-	if len(respBody) > 0 {
-		var bodyMap map[string]interface{}
-		if err := json.Unmarshal(respBody, &bodyMap); err != nil {
-			c.logger.Error("解析响应体失败", zap.Error(err))
-			return nil, NewJPushError(ErrorCodeInvalidJSON, "响应体解析失败")
-		}
-		apiResp.Body = bodyMap
-
-		// 检查是否有错误
-		if errorData, exists := bodyMap["error"]; exists {
-			if errorMap, ok := errorData.(map[string]interface{}); ok {
-				code := ErrorCodeInternalError
-				message := "未知错误"
-
-				if codeFloat, exists := errorMap["code"]; exists {
-					if codeVal, ok := codeFloat.(float64); ok {
-						code = ErrorCode(int(codeVal))
-					}
-				}
-
-				if msgStr, exists := errorMap["message"]; exists {
-					if msgVal, ok := msgStr.(string); ok {
-						message = msgVal
-					}
-				}
-
-				apiResp.Error = NewJPushError(code, message)
-			}
+	// 校验响应体并检查是否有错误，具体结构由调用方按目标类型解码
+	if len(bytes.TrimSpace(respBody)) > 0 {
+		var raw json.RawMessage
+		if err := json.Unmarshal(respBody, &raw); err != nil {
+			c.logger.Error("解析响应体失败", "error", err)
+			jpushErr := wrapJPushError(ErrorCodeInvalidJSON, "响应体解析失败", err)
+			return nil, withResponseInfo(jpushErr, path, resp.StatusCode, resp.Header, c.redactor.errorBody(respBody))
 		}
+		apiResp.Body = respBody
+		apiResp.Error = parseErrorEnvelope(respBody)
 	}
 
 	// 检查HTTP状态码
 	if resp.StatusCode >= 400 {
 		if apiResp.Error == nil {
 			message := fmt.Sprintf("HTTP错误: %d", resp.StatusCode)
-			switch resp.StatusCode {
-			case 400:
-				apiResp.Error = NewJPushError(ErrorCodeInvalidParams, message)
-			case 401:
-				apiResp.Error = NewJPushError(ErrorCodeInvalidAuth, message)
-			case 403:
-				apiResp.Error = NewJPushError(ErrorCodeAppKeyBlacklisted, message)
-			case 429:
-				apiResp.Error = NewJPushError(ErrorCodeRateLimitExceeded, message)
-			default:
-				apiResp.Error = NewJPushError(ErrorCodeInternalError, message)
-			}
-		}
-		c.logger.Error("API请求失败", zap.Any("error", apiResp.Error))
+			apiResp.Error = NewJPushError(GetErrorCodeFromHTTPStatus(resp.StatusCode), message)
+		}
+		withResponseInfo(apiResp.Error, path, resp.StatusCode, resp.Header, c.redactor.errorBody(respBody))
+		c.logger.Error("API请求失败", "error", apiResp.Error)
 		return apiResp, apiResp.Error
 	}
 
@@ -203,33 +437,87 @@ func (c *Client) makeReportRequest(method, path string, body interface{}) (*APIR
 	return c.makeRequestWithoutContext(method, c.baseURLs["report"], path, body)
 }
 
+// makeReportRequestContext 发送Report API请求，ctx用于传递追踪信息
+func (c *Client) makeReportRequestContext(ctx context.Context, method, path string, body interface{}) (*APIResponse, error) {
+	return c.makeRequest(ctx, method, c.baseURLs["report"], path, body)
+}
+
 // makePushRequest 发送Push API请求
 func (c *Client) makePushRequest(method, path string, body interface{}) (*APIResponse, error) {
 	return c.makeRequestWithoutContext(method, c.baseURLs["push"], path, body)
 }
 
+// makePushRequestContext 发送Push API请求，ctx用于传递追踪信息
+func (c *Client) makePushRequestContext(ctx context.Context, method, path string, body interface{}) (*APIResponse, error) {
+	return c.makeRequest(ctx, method, c.baseURLs["push"], path, body)
+}
+
 // makeDeviceRequest 发送Device API请求
 func (c *Client) makeDeviceRequest(method, path string, body interface{}) (*APIResponse, error) {
 	return c.makeRequestWithoutContext(method, c.baseURLs["device"], path, body)
 }
 
+// makeScheduleRequest 发送Schedule API请求
+func (c *Client) makeScheduleRequest(method, path string, body interface{}) (*APIResponse, error) {
+	return c.makeRequestWithoutContext(method, c.baseURLs["schedule"], path, body)
+}
+
+// makeAdminRequest 发送Admin API请求
+func (c *Client) makeAdminRequest(method, path string, body interface{}) (*APIResponse, error) {
+	return c.makeRequestWithoutContext(method, c.baseURLs["admin"], path, body)
+}
+
 // GetRateLimitInfo 获取频率限制信息
 func (c *Client) GetRateLimitInfo(resp *APIResponse) (limit, remaining, reset int) {
 	if resp == nil || resp.Headers == nil {
 		return 0, 0, 0
 	}
 
-	if limitHeaders := resp.Headers["X-Rate-Limit-Limit"]; len(limitHeaders) > 0 {
-		fmt.Sscanf(limitHeaders[0], "%d", &limit)
+	info := parseRateLimit(resp.Headers)
+	return info.Limit, info.Remaining, info.Reset
+}
+
+// parseRateLimit 从响应头解析频率限制信息
+func parseRateLimit(headers map[string][]string) RateLimitInfo {
+	var info RateLimitInfo
+
+	if limitHeaders := headers["X-Rate-Limit-Limit"]; len(limitHeaders) > 0 {
+		fmt.Sscanf(limitHeaders[0], "%d", &info.Limit)
 	}
 
-	if remainingHeaders := resp.Headers["X-Rate-Limit-Remaining"]; len(remainingHeaders) > 0 {
-		fmt.Sscanf(remainingHeaders[0], "%d", &remaining)
+	if remainingHeaders := headers["X-Rate-Limit-Remaining"]; len(remainingHeaders) > 0 {
+		fmt.Sscanf(remainingHeaders[0], "%d", &info.Remaining)
 	}
 
-	if resetHeaders := resp.Headers["X-Rate-Limit-Reset"]; len(resetHeaders) > 0 {
-		fmt.Sscanf(resetHeaders[0], "%d", &reset)
+	if resetHeaders := headers["X-Rate-Limit-Reset"]; len(resetHeaders) > 0 {
+		fmt.Sscanf(resetHeaders[0], "%d", &info.Reset)
 	}
 
-	return limit, remaining, reset
+	return info
+}
+
+// withRequestPath 为错误补充请求路径
+func withRequestPath(err *JPushError, path string) *JPushError {
+	err.Path = path
+	return err
+}
+
+// withResponseInfo 为错误补充请求路径、HTTP状态码、响应体片段和频率限制信息
+// body应已经过脱敏
+func withResponseInfo(err *JPushError, path string, statusCode int, headers http.Header, body []byte) *JPushError {
+	err.Path = path
+	err.HTTPStatus = statusCode
+	if len(body) > maxErrorBodySnippet {
+		body = body[:maxErrorBodySnippet]
+		// 避免截断多字节字符
+		for len(body) > 0 && !utf8.Valid(body) {
+			body = body[:len(body)-1]
+		}
+	}
+	err.Body = string(body)
+	if len(headers["X-Rate-Limit-Limit"]) > 0 || len(headers["X-Rate-Limit-Remaining"]) > 0 {
+		info := parseRateLimit(headers)
+		err.RateLimit = &info
+	}
+	return err
 }
\ No newline at end of file
@@ -1,8 +1,12 @@
 package goserversdk
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -23,7 +27,7 @@ func TestNewClient(t *testing.T) {
 
 func TestNewClient_ValidationErrors(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
-	
+
 	tests := []struct {
 		name        string
 		config      *Config
@@ -66,15 +70,14 @@ func TestNewClient_ValidationErrors(t *testing.T) {
 				MasterSecret: "test-master-secret",
 				Logger:       nil,
 			},
-			wantErr:     true,
-			expectedErr: ErrorCodeInvalidParams,
+			wantErr: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client, err := NewClient(tt.config)
-			
+
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, client)
@@ -96,7 +99,7 @@ func TestNewClient_ValidationErrors(t *testing.T) {
 func TestNewClientWithTimeout(t *testing.T) {
 	testConfig, err := LoadTestConfig()
 	assert.NoError(t, err)
-	
+
 	logger, _ := zap.NewDevelopment()
 	config := &Config{
 		AppKey:       testConfig.AppKey,
@@ -104,7 +107,7 @@ func TestNewClientWithTimeout(t *testing.T) {
 		Logger:       logger,
 		Timeout:      10 * time.Second,
 	}
-	
+
 	client, err := NewClient(config)
 	assert.NoError(t, err)
 	assert.NotNil(t, client)
@@ -141,7 +144,7 @@ func TestClient_MakeRequest_HTTPError(t *testing.T) {
 
 	_, err = client.makeRequestWithoutContext("GET", server.URL, "/test", nil)
 	assert.Error(t, err)
-	
+
 	if jpushErr, ok := err.(*JPushError); ok {
 		assert.Equal(t, ErrorCode(1000), jpushErr.Code)
 	}
@@ -160,7 +163,7 @@ func TestClient_MakeRequest_InvalidJSON(t *testing.T) {
 
 	_, err = client.makeRequestWithoutContext("GET", server.URL, "/test", nil)
 	assert.Error(t, err)
-	
+
 	if jpushErr, ok := err.(*JPushError); ok {
 		assert.Equal(t, ErrorCodeInvalidJSON, jpushErr.Code)
 	}
@@ -175,10 +178,281 @@ func TestClient_MakeRequest_Timeout(t *testing.T) {
 
 	client, err := NewTestClient()
 	assert.NoError(t, err)
-	
+
 	// 设置超时时间
 	client.httpClient.Timeout = 1 * time.Millisecond
 
 	_, err = client.makeRequestWithoutContext("GET", server.URL, "/test", nil)
 	assert.Error(t, err)
-}
\ No newline at end of file
+}
+
+func TestClient_MakeRequest_ErrorDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Limit", "600")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"code": 2002, "message": "Rate limit exceeded"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	_, err = client.makeRequestWithoutContext("GET", server.URL, "/v3/push/cid", nil)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRateLimited))
+
+	var jpushErr *JPushError
+	assert.True(t, errors.As(err, &jpushErr))
+	assert.Equal(t, http.StatusTooManyRequests, jpushErr.HTTPStatus)
+	assert.Equal(t, "/v3/push/cid", jpushErr.Path)
+	assert.Contains(t, jpushErr.Body, "Rate limit exceeded")
+	assert.Equal(t, &RateLimitInfo{Limit: 600, Remaining: 0, Reset: 30}, jpushErr.RateLimit)
+}
+
+func TestClient_MakeRequest_WrapsTransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	_, err = client.makeRequestWithoutContext("GET", serverURL, "/test", nil)
+	assert.Error(t, err)
+
+	var jpushErr *JPushError
+	assert.True(t, errors.As(err, &jpushErr))
+	assert.NotNil(t, jpushErr.Cause)
+	assert.Equal(t, "/test", jpushErr.Path)
+
+	var urlErr *url.Error
+	assert.True(t, errors.As(err, &urlErr))
+}
+
+func TestClient_MakeRequest_TransportErrorCodes(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowServer.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer tlsServer.Close()
+
+	closedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closedURL := closedServer.URL
+	closedServer.Close()
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		baseURL string
+		timeout time.Duration
+		code    ErrorCode
+	}{
+		{name: "client timeout", ctx: context.Background(), baseURL: slowServer.URL, timeout: time.Millisecond, code: ErrorCodeRequestTimeout},
+		{name: "canceled", ctx: canceledCtx, baseURL: slowServer.URL, code: ErrorCodeCanceled},
+		{name: "connection refused", ctx: context.Background(), baseURL: closedURL, code: ErrorCodeNetwork},
+		{name: "untrusted certificate", ctx: context.Background(), baseURL: tlsServer.URL, code: ErrorCodeTLS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewTestClient()
+			assert.NoError(t, err)
+			if tt.timeout > 0 {
+				client.httpClient.Timeout = tt.timeout
+			}
+
+			_, err = client.makeRequest(tt.ctx, "GET", tt.baseURL, "/test", nil)
+			assert.Error(t, err)
+			assert.Equal(t, tt.code, GetErrorCode(err))
+
+			var jpushErr *JPushError
+			assert.True(t, errors.As(err, &jpushErr))
+			assert.True(t, jpushErr.IsLocal())
+		})
+	}
+}
+
+func TestAPIResponse_Decode(t *testing.T) {
+	resp := &APIResponse{Body: []byte(`{"big": 18014398509481985, "extras": {"id": 18014398509481985}}`)}
+
+	var result struct {
+		Big    int64                  `json:"big"`
+		Extras map[string]interface{} `json:"extras"`
+	}
+	assert.NoError(t, resp.Decode(&result))
+	assert.Equal(t, int64(18014398509481985), result.Big)
+	// interface{}中的数字保留为json.Number
+	assert.Equal(t, json.Number("18014398509481985"), result.Extras["id"])
+
+	// 空响应体不修改目标
+	assert.NoError(t, (&APIResponse{}).Decode(&result))
+}
+
+func TestDecodeResponseList(t *testing.T) {
+	list, err := decodeResponseList[PushResponse](&APIResponse{Body: []byte(`[{"msg_id": "1"}, {"msg_id": 2}]`)})
+	assert.NoError(t, err)
+	assert.Equal(t, []PushResponse{{MsgID: "1"}, {MsgID: "2"}}, list)
+
+	// 单个对象按一个元素处理
+	list, err = decodeResponseList[PushResponse](&APIResponse{Body: []byte(`{"msg_id": "3"}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, []PushResponse{{MsgID: "3"}}, list)
+
+	list, err = decodeResponseList[PushResponse](&APIResponse{})
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	_, err = decodeResponseList[PushResponse](&APIResponse{Body: []byte(`"text"`)})
+	assert.Error(t, err)
+}
+
+func TestClient_MakeRequest_ArrayBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"msg_id": 18014398509481985}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	resp, err := client.makeRequestWithoutContext("GET", server.URL, "/v3/received", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, `[{"msg_id": 18014398509481985}]`, string(resp.Body))
+}
+
+// roundTripFunc 用于测试的自定义传输层
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewClient_CustomTransport(t *testing.T) {
+	var gotUA, gotURL string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotUA = req.Header.Get("User-Agent")
+		gotURL = req.URL.String()
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(http.StatusOK)
+		rec.WriteString(`{"cidlist":["c1"]}`)
+		return rec.Result(), nil
+	})
+
+	logger, _ := zap.NewDevelopment()
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		Logger:       logger,
+		Transport:    transport,
+		UserAgent:    "my-service/1.0",
+		BaseURLs:     &BaseURLs{Push: "https://gateway.example.com/"},
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Advanced.GetCID(1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-service/1.0", gotUA)
+	assert.Equal(t, "https://gateway.example.com/v3/push/cid?count=1", gotURL)
+
+	// 未覆盖的域名使用默认值
+	assert.Equal(t, "https://report.jpush.cn", client.baseURLs["report"])
+	assert.Equal(t, "https://admin.jpush.cn", client.baseURLs["admin"])
+}
+
+func TestNewClient_DefaultUserAgent(t *testing.T) {
+	var gotUA string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"cidlist":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+	client.baseURLs["push"] = server.URL
+
+	_, err = client.Advanced.GetCID(1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultUserAgent, gotUA)
+}
+
+func TestNewClient_HTTPClientAndProxy(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	httpClient := &http.Client{Timeout: time.Second}
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		Logger:       logger,
+		HTTPClient:   httpClient,
+	})
+	assert.NoError(t, err)
+	assert.Same(t, httpClient, client.httpClient)
+
+	proxyURL, _ := url.Parse("http://proxy.example.com:8080")
+	client, err = NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		Logger:       logger,
+		Proxy:        http.ProxyURL(proxyURL),
+	})
+	assert.NoError(t, err)
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	assert.True(t, ok)
+	got, err := transport.Proxy(httptest.NewRequest(http.MethodGet, "https://api.jpush.cn", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, proxyURL, got)
+}
+
+func TestNewClient_InvalidBaseURL(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	_, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		Logger:       logger,
+		BaseURLs:     &BaseURLs{Report: "report.jpush.cn"},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+}
+
+func TestNewClient_Region(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		Logger:       logger,
+		Region:       RegionHK,
+		BaseURLs:     &BaseURLs{Report: "https://report.example.com"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.hk.jpush.cn", client.baseURLs["push"])
+	assert.Equal(t, "https://device.hk.jpush.cn", client.baseURLs["device"])
+	assert.Equal(t, "https://api.hk.jpush.cn", client.baseURLs["schedule"])
+	assert.Equal(t, "https://report.example.com", client.baseURLs["report"])
+
+	// 默认使用中国大陆数据中心
+	client, err = NewTestClient()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.jpush.cn", client.baseURLs["push"])
+	assert.Equal(t, "https://api.jpush.cn", client.baseURLs["schedule"])
+
+	_, err = NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		Logger:       logger,
+		Region:       Region("eu"),
+	})
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+}
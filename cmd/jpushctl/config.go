@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultEnvFile 默认的认证配置文件，格式与SDK测试使用的env.test一致
+const defaultEnvFile = "env.test"
+
+// credentials 认证信息
+type credentials struct {
+	AppKey       string
+	MasterSecret string
+}
+
+// loadCredentials 按参数、环境变量、配置文件的优先级读取认证信息
+// 默认配置文件不存在时忽略，显式指定的配置文件不存在时报错
+func loadCredentials(envFile, appKey, masterSecret string) (*credentials, error) {
+	creds := &credentials{AppKey: appKey, MasterSecret: masterSecret}
+	if creds.AppKey == "" {
+		creds.AppKey = os.Getenv("JPUSH_APP_KEY")
+	}
+	if creds.MasterSecret == "" {
+		creds.MasterSecret = os.Getenv("JPUSH_MASTER_SECRET")
+	}
+
+	if (creds.AppKey == "" || creds.MasterSecret == "") && envFile != "" {
+		values, err := readEnvFile(envFile)
+		if err != nil && !(errors.Is(err, os.ErrNotExist) && envFile == defaultEnvFile) {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		if creds.AppKey == "" {
+			creds.AppKey = values["APP_KEY"]
+		}
+		if creds.MasterSecret == "" {
+			creds.MasterSecret = values["MASTER_SECRET"]
+		}
+	}
+
+	if creds.AppKey == "" || creds.MasterSecret == "" {
+		return nil, errors.New("缺少认证信息，请设置-app-key/-master-secret、JPUSH_APP_KEY/JPUSH_MASTER_SECRET或env.test")
+	}
+	return creds, nil
+}
+
+// readEnvFile 读取KEY=VALUE格式的配置文件，忽略空行和#开头的注释
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return values, scanner.Err()
+}
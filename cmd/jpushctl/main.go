@@ -0,0 +1,188 @@
+// Command jpushctl 是基于SDK的JPush命令行工具，用于发送测试推送和查询统计
+//
+// 用法:
+//
+//	jpushctl [全局参数] <命令> [参数]
+//
+// 命令:
+//
+//	push      发送推送，推送内容来自参数或JSON文件
+//	validate  校验推送请求，不实际下发
+//	cancel    撤销推送
+//	cid       获取推送唯一标识符
+//	quota     查询厂商配额
+//	report    查询统计：received、detail、status
+//
+// 认证信息按以下顺序读取：-app-key/-master-secret参数、JPUSH_APP_KEY/JPUSH_MASTER_SECRET环境变量、
+// env.test格式的配置文件（APP_KEY=...、MASTER_SECRET=...）。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+)
+
+// 退出码
+const (
+	exitOK    = 0
+	exitError = 1 // 请求失败
+	exitUsage = 2 // 参数错误
+)
+
+// errUsage 参数错误，已输出用法说明
+var errUsage = errors.New("usage error")
+
+// app 命令执行环境
+type app struct {
+	client *goserversdk.Client
+	format string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command 子命令
+type command struct {
+	name    string
+	summary string
+	run     func(a *app, args []string) error
+}
+
+var commands = []command{
+	{"push", "发送推送", runPush},
+	{"validate", "校验推送请求，不实际下发", runValidate},
+	{"cancel", "撤销推送: cancel <msg_id>", runCancel},
+	{"cid", "获取推送唯一标识符", runCID},
+	{"quota", "查询厂商配额", runQuota},
+	{"report", "查询统计: report received|detail|status", runReport},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 解析全局参数并执行子命令，返回退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("jpushctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	envFile := fs.String("env", defaultEnvFile, "env.test格式的认证配置文件")
+	appKey := fs.String("app-key", "", "应用AppKey，默认读取JPUSH_APP_KEY")
+	masterSecret := fs.String("master-secret", "", "应用MasterSecret，默认读取JPUSH_MASTER_SECRET")
+	region := fs.String("region", "", "数据中心区域：cn、hk")
+	baseURL := fs.String("base-url", "", "所有服务使用的域名，如测试环境地址")
+	format := fs.String("o", "table", "输出格式：table、json")
+	timeout := fs.Duration("timeout", 30*time.Second, "请求超时时间")
+	dryRun := fs.String("dry-run", "", "试运行模式：validate、local")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "用法: jpushctl [全局参数] <命令> [参数]")
+		fmt.Fprintln(stderr, "\n命令:")
+		for _, c := range commands {
+			fmt.Fprintf(stderr, "  %-10s %s\n", c.name, c.summary)
+		}
+		fmt.Fprintln(stderr, "\n全局参数:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "jpushctl: 不支持的输出格式 %q\n", *format)
+		return exitUsage
+	}
+
+	name := fs.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "jpushctl: 未知命令 %q\n", name)
+		fs.Usage()
+		return exitUsage
+	}
+
+	creds, err := loadCredentials(*envFile, *appKey, *masterSecret)
+	if err != nil {
+		fmt.Fprintf(stderr, "jpushctl: %v\n", err)
+		return exitUsage
+	}
+
+	config := &goserversdk.Config{
+		AppKey:       creds.AppKey,
+		MasterSecret: creds.MasterSecret,
+		Timeout:      *timeout,
+		Region:       goserversdk.Region(*region),
+		UserAgent:    "jpushctl",
+	}
+	if *baseURL != "" {
+		config.BaseURLs = &goserversdk.BaseURLs{Push: *baseURL, Device: *baseURL, Report: *baseURL, Schedule: *baseURL, Admin: *baseURL}
+	}
+	switch *dryRun {
+	case "":
+	case "validate":
+		config.DryRun = goserversdk.DryRunValidate
+	case "local":
+		config.DryRun = goserversdk.DryRunLocal
+	default:
+		fmt.Fprintf(stderr, "jpushctl: 不支持的试运行模式 %q\n", *dryRun)
+		return exitUsage
+	}
+
+	client, err := goserversdk.NewClient(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "jpushctl: %v\n", err)
+		return exitUsage
+	}
+
+	a := &app{client: client, format: *format, stdin: stdin, stdout: stdout, stderr: stderr}
+	if err := cmd.run(a, fs.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			return exitUsage
+		}
+		fmt.Fprintf(stderr, "jpushctl %s: %v\n", name, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// newFlagSet 创建子命令参数集
+func (a *app) newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "用法: jpushctl %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags 解析子命令参数，参数错误统一返回errUsage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// usageError 输出参数错误和用法说明
+func (a *app) usageError(fs *flag.FlagSet, format string, args ...interface{}) error {
+	fmt.Fprintf(a.stderr, "jpushctl %s: %s\n", fs.Name(), fmt.Sprintf(format, args...))
+	fs.Usage()
+	return errUsage
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mimicode/jpush-go-sdk/jpushtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// runCLI 以模拟服务的认证信息执行命令
+func runCLI(t *testing.T, srv *jpushtest.Server, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	global := []string{"-env", "", "-app-key", jpushtest.DefaultAppKey, "-master-secret", jpushtest.DefaultMasterSecret, "-base-url", srv.URL()}
+	code := run(append(global, args...), strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestPushAndReport(t *testing.T) {
+	srv := jpushtest.NewServer()
+	defer srv.Close()
+	srv.AddDevice(
+		jpushtest.Device{RegistrationID: "reg-a", Alias: "alice", Platform: "android"},
+		jpushtest.Device{RegistrationID: "reg-b", Alias: "bob", Platform: "ios"},
+	)
+
+	code, out, errOut := runCLI(t, srv, "", "-o", "json", "push", "-alias", "alice", "-alert", "hello", "-title", "greeting", "-extras", `{"k":"v"}`)
+	assert.Equal(t, exitOK, code, errOut)
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &resp))
+	msgID := resp["msg_id"].(string)
+	assert.Equal(t, false, resp["dry_run"])
+
+	srv.AssertReceived(t, "alice", "hello")
+	srv.AssertNotReceived(t, "bob", "hello")
+	push := srv.LastPush()
+	assert.Equal(t, "greeting", *push.Request.Notification.Android.Title)
+	assert.Equal(t, "v", push.Request.Notification.IOS.Extras["k"])
+
+	code, out, _ = runCLI(t, srv, "", "report", "received", msgID)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, out, "JPUSH_RECEIVED")
+	assert.Contains(t, out, msgID)
+
+	code, out, _ = runCLI(t, srv, "", "report", "detail", msgID)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, out, "notification")
+
+	code, out, _ = runCLI(t, srv, "", "report", "status", "-msg-id", msgID, "-rid", "reg-a,reg-b")
+	assert.Equal(t, exitOK, code)
+	assert.Regexp(t, `reg-a\s+delivered`, out)
+	assert.Regexp(t, `reg-b\s+not_target`, out)
+
+	code, out, _ = runCLI(t, srv, "", "cancel", msgID)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, out, msgID)
+	assert.True(t, srv.PushByID(msgID).Cancelled)
+}
+
+func TestPushFromFile(t *testing.T) {
+	srv := jpushtest.NewServer()
+	defer srv.Close()
+	srv.AddDevice(jpushtest.Device{RegistrationID: "reg-a", Tags: []string{"news"}})
+
+	file := filepath.Join(t.TempDir(), "push.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"platform":"all","audience":"all","notification":{"alert":"from file"}}`), 0o644))
+
+	code, out, errOut := runCLI(t, srv, "", "push", "-file", file)
+	assert.Equal(t, exitOK, code, errOut)
+	assert.Contains(t, out, "MSG_ID")
+	srv.AssertReceived(t, "reg-a", "from file")
+
+	// 标准输入，参数覆盖文件中的字段
+	code, _, errOut = runCLI(t, srv, `{"platform":["android"],"audience":{"tag":["news"]},"message":{"msg_content":"stdin"}}`,
+		"push", "-file", "-", "-alert", "override")
+	assert.Equal(t, exitOK, code, errOut)
+	srv.AssertReceived(t, "reg-a", "stdin")
+	srv.AssertReceived(t, "reg-a", "override")
+
+	// validate不记录推送
+	count := len(srv.Pushes())
+	code, _, errOut = runCLI(t, srv, "", "validate", "-rid", "reg-a", "-alert", "check")
+	assert.Equal(t, exitOK, code, errOut)
+	assert.Len(t, srv.Pushes(), count)
+}
+
+func TestPushTitle(t *testing.T) {
+	srv := jpushtest.NewServer()
+	defer srv.Close()
+	srv.AddDevice(jpushtest.Device{RegistrationID: "reg-a", Platform: "hmos"})
+
+	code, _, errOut := runCLI(t, srv, "", "push", "-rid", "reg-a", "-alert", "hi", "-title", "greeting")
+	assert.Equal(t, exitOK, code, errOut)
+	push := srv.LastPush()
+	assert.Equal(t, "greeting", *push.Request.Notification.Android.Title)
+	assert.Equal(t, "greeting", *push.Request.Notification.HMOS.Title)
+
+	// 没有-alert或-message时标题不会生效
+	file := filepath.Join(t.TempDir(), "push.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"platform":"all","audience":"all","notification":{"alert":"from file"}}`), 0o644))
+	code, _, errOut = runCLI(t, srv, "", "push", "-file", file, "-title", "ignored")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, errOut, "-title")
+}
+
+func TestReportPartialFailure(t *testing.T) {
+	srv := jpushtest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(jpushtest.Fault{Path: "/v3/received/detail", Status: http.StatusBadRequest, Code: 1003, Times: 1})
+
+	ids := make([]string, 150)
+	for i := range ids {
+		ids[i] = strconv.Itoa(18100000000000001 + i)
+	}
+	code, out, errOut := runCLI(t, srv, "", "report", "received", strings.Join(ids, ","))
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, errOut, "失败的ID")
+	// 成功批次的结果仍然输出
+	assert.Contains(t, out, "MSG_ID")
+	rows := len(strings.Split(strings.TrimSpace(out), "\n")) - 1
+	assert.True(t, rows == 50 || rows == 100, "rows: %d", rows)
+}
+
+func TestCIDQuotaAndErrors(t *testing.T) {
+	srv := jpushtest.NewServer()
+	defer srv.Close()
+
+	code, out, _ := runCLI(t, srv, "", "cid", "-count", "2")
+	assert.Equal(t, exitOK, code)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 3)
+
+	// 模拟服务没有实现配额接口，返回错误
+	code, _, errOut := runCLI(t, srv, "", "quota")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, errOut, "jpushctl quota:")
+
+	// 没有匹配的推送目标
+	code, _, errOut = runCLI(t, srv, "", "push", "-tag", "nobody", "-alert", "hi")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, errOut, "1011")
+
+	code, _, _ = runCLI(t, srv, "", "push", "-alert", "hi")
+	assert.Equal(t, exitUsage, code)
+	code, _, _ = runCLI(t, srv, "", "report", "unknown")
+	assert.Equal(t, exitUsage, code)
+	code, _, _ = runCLI(t, srv, "", "nope")
+	assert.Equal(t, exitUsage, code)
+
+	// 本地试运行不访问服务
+	requests := len(srv.Requests())
+	code, out, _ = runCLI(t, srv, "", "-dry-run", "local", "push", "-rid", "reg", "-alert", "hi")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, out, "true")
+	assert.Len(t, srv.Requests(), requests)
+}
+
+func TestLoadCredentials(t *testing.T) {
+	t.Setenv("JPUSH_APP_KEY", "")
+	t.Setenv("JPUSH_MASTER_SECRET", "")
+
+	file := filepath.Join(t.TempDir(), "env.test")
+	assert.NoError(t, os.WriteFile(file, []byte("# comment\nAPP_KEY=file-key\nMASTER_SECRET=\"file-secret\"\n"), 0o644))
+
+	creds, err := loadCredentials(file, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "file-key", creds.AppKey)
+	assert.Equal(t, "file-secret", creds.MasterSecret)
+
+	t.Setenv("JPUSH_APP_KEY", "env-key")
+	creds, err = loadCredentials(file, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "env-key", creds.AppKey)
+	assert.Equal(t, "file-secret", creds.MasterSecret)
+
+	creds, err = loadCredentials(file, "flag-key", "flag-secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "flag-key", creds.AppKey)
+
+	// 显式指定的文件不存在时报错
+	_, err = loadCredentials(filepath.Join(t.TempDir(), "missing"), "", "")
+	assert.Error(t, err)
+
+	t.Setenv("JPUSH_APP_KEY", "")
+	_, err = loadCredentials("", "", "")
+	assert.Error(t, err)
+}
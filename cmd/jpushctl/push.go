@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+)
+
+// pushFlags 构建推送请求的参数
+type pushFlags struct {
+	fs             *flag.FlagSet
+	file           string
+	platform       string
+	all            bool
+	tag            string
+	tagAnd         string
+	tagNot         string
+	alias          string
+	rid            string
+	alert          string
+	title          string
+	message        string
+	extras         string
+	cid            string
+	ttl            int
+	apnsProduction bool
+}
+
+// newPushFlags 注册推送请求参数
+func newPushFlags(a *app, name string) *pushFlags {
+	p := &pushFlags{fs: a.newFlagSet(name, name+" [参数]")}
+	p.fs.StringVar(&p.file, "file", "", "JSON格式的推送请求文件，-表示标准输入；其他参数覆盖文件中的对应字段")
+	p.fs.StringVar(&p.platform, "platform", "", "推送平台，逗号分隔，如android,ios，默认all")
+	p.fs.BoolVar(&p.all, "all", false, "广播推送给所有设备")
+	p.fs.StringVar(&p.tag, "tag", "", "标签OR，逗号分隔")
+	p.fs.StringVar(&p.tagAnd, "tag-and", "", "标签AND，逗号分隔")
+	p.fs.StringVar(&p.tagNot, "tag-not", "", "标签NOT，逗号分隔")
+	p.fs.StringVar(&p.alias, "alias", "", "别名，逗号分隔")
+	p.fs.StringVar(&p.rid, "rid", "", "设备注册ID，逗号分隔")
+	p.fs.StringVar(&p.alert, "alert", "", "通知内容")
+	p.fs.StringVar(&p.title, "title", "", "通知标题（Android、鸿蒙）或自定义消息标题")
+	p.fs.StringVar(&p.message, "message", "", "自定义消息内容")
+	p.fs.StringVar(&p.extras, "extras", "", "附加字段，JSON对象")
+	p.fs.StringVar(&p.cid, "cid", "", "防重复标识")
+	p.fs.IntVar(&p.ttl, "ttl", 0, "离线保留时长（秒）")
+	p.fs.BoolVar(&p.apnsProduction, "apns-production", false, "使用APNs生产环境")
+	return p
+}
+
+// build 解析参数并构建推送请求
+func (p *pushFlags) build(a *app, args []string) (*goserversdk.PushRequest, error) {
+	if err := parseFlags(p.fs, args); err != nil {
+		return nil, err
+	}
+	if p.fs.NArg() > 0 {
+		return nil, a.usageError(p.fs, "多余的参数 %q", p.fs.Args())
+	}
+
+	req := goserversdk.NewPushRequest()
+	if p.file != "" {
+		var err error
+		if req, err = readPushRequest(a.stdin, p.file); err != nil {
+			return nil, err
+		}
+	}
+
+	set := make(map[string]bool)
+	p.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if p.platform != "" {
+		req.Platform = goserversdk.NewSpecificPlatforms(splitList(p.platform)...).GetPlatforms()
+	}
+	if req.Platform == nil {
+		req.Platform = goserversdk.NewAllPlatform().GetPlatforms()
+	}
+
+	if audience := p.audience(); audience != nil {
+		req.Audience = audience
+	}
+	if req.Audience == nil {
+		return nil, a.usageError(p.fs, "需要指定推送目标：-all、-tag、-tag-and、-tag-not、-alias或-rid")
+	}
+
+	var extras map[string]interface{}
+	if p.extras != "" {
+		if err := json.Unmarshal([]byte(p.extras), &extras); err != nil {
+			return nil, a.usageError(p.fs, "-extras不是合法的JSON对象: %v", err)
+		}
+	}
+
+	if p.title != "" && p.alert == "" && p.message == "" {
+		return nil, a.usageError(p.fs, "-title需要与-alert或-message一起使用")
+	}
+
+	if p.alert != "" {
+		if req.Notification == nil {
+			req.Notification = &goserversdk.Notification{}
+		}
+		req.Notification.Alert = p.alert
+		if p.title != "" || extras != nil {
+			android := &goserversdk.AndroidNotification{Alert: p.alert, Extras: extras}
+			hmos := &goserversdk.HMOSNotification{Alert: p.alert, Extras: extras}
+			if p.title != "" {
+				android.Title = &p.title
+				hmos.Title = &p.title
+			}
+			req.Notification.Android = android
+			req.Notification.HMOS = hmos
+		}
+		if extras != nil {
+			req.Notification.IOS = &goserversdk.IOSNotification{Alert: p.alert, Extras: extras}
+		}
+	}
+	if p.message != "" {
+		req.Message = &goserversdk.Message{MsgContent: p.message, Extras: extras}
+		if p.title != "" {
+			req.Message.Title = &p.title
+		}
+	}
+	if req.Notification == nil && req.Message == nil {
+		return nil, a.usageError(p.fs, "需要指定-alert或-message")
+	}
+
+	if set["ttl"] || set["apns-production"] {
+		if req.Options == nil {
+			req.Options = &goserversdk.Options{}
+		}
+		if set["ttl"] {
+			req.Options.TimeToLive = &p.ttl
+		}
+		if set["apns-production"] {
+			req.Options.APNSProduction = &p.apnsProduction
+		}
+	}
+	if p.cid != "" {
+		req.SetCID(p.cid)
+	}
+	return req, nil
+}
+
+// audience 根据参数构建推送目标，未指定任何目标时返回nil
+func (p *pushFlags) audience() *goserversdk.Audience {
+	if p.all {
+		return goserversdk.NewBroadcastAudience()
+	}
+	audience := &goserversdk.Audience{
+		Tag:            splitList(p.tag),
+		TagAnd:         splitList(p.tagAnd),
+		TagNot:         splitList(p.tagNot),
+		Alias:          splitList(p.alias),
+		RegistrationID: splitList(p.rid),
+	}
+	if len(audience.Tag)+len(audience.TagAnd)+len(audience.TagNot)+len(audience.Alias)+len(audience.RegistrationID) == 0 {
+		return nil
+	}
+	return audience
+}
+
+// pushRequestFile 推送请求文件格式，audience兼容JPush文档中的字符串"all"
+type pushRequestFile struct {
+	goserversdk.PushRequest
+	Audience json.RawMessage `json:"audience"`
+}
+
+// readPushRequest 读取JSON格式的推送请求文件
+func readPushRequest(stdin io.Reader, path string) (*goserversdk.PushRequest, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取推送请求文件失败: %w", err)
+	}
+
+	var file pushRequestFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析推送请求文件失败: %w", err)
+	}
+	req := file.PushRequest
+	var all string
+	switch {
+	case len(file.Audience) == 0 || string(file.Audience) == "null":
+		req.Audience = nil
+	case json.Unmarshal(file.Audience, &all) == nil && all == "all":
+		req.Audience = goserversdk.NewBroadcastAudience()
+	default:
+		req.Audience = &goserversdk.Audience{}
+		if err := json.Unmarshal(file.Audience, req.Audience); err != nil {
+			return nil, fmt.Errorf("解析推送目标失败: %w", err)
+		}
+	}
+	return &req, nil
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runPush 执行push命令
+func runPush(a *app, args []string) error {
+	req, err := newPushFlags(a, "push").build(a, args)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Push.Push(req)
+	if err != nil {
+		return err
+	}
+	return a.printPushResponse(resp)
+}
+
+// runValidate 执行validate命令
+func runValidate(a *app, args []string) error {
+	req, err := newPushFlags(a, "validate").build(a, args)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Advanced.ValidatePush(req)
+	if err != nil {
+		return err
+	}
+	return a.printPushResponse(resp)
+}
+
+// runCancel 执行cancel命令
+func runCancel(a *app, args []string) error {
+	fs := a.newFlagSet("cancel", "cancel <msg_id>")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return a.usageError(fs, "需要指定一个msg_id")
+	}
+	msgID := fs.Arg(0)
+	if err := a.client.Advanced.CancelPush(msgID); err != nil {
+		return err
+	}
+	if a.format == "json" {
+		return a.printJSON(map[string]interface{}{"msg_id": msgID, "cancelled": true})
+	}
+	fmt.Fprintf(a.stdout, "已撤销 %s\n", msgID)
+	return nil
+}
+
+// runCID 执行cid命令
+func runCID(a *app, args []string) error {
+	fs := a.newFlagSet("cid", "cid [-count N] [-type push|schedule]")
+	count := fs.Int("count", 1, "CID数量")
+	cidType := fs.String("type", "", "CID类型：push、schedule")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	resp, err := a.client.Advanced.GetCID(*count, goserversdk.CIDType(*cidType))
+	if err != nil {
+		return err
+	}
+	if a.format == "json" {
+		return a.printJSON(resp)
+	}
+	rows := make([][]string, 0, len(resp.CIDList))
+	for _, cid := range resp.CIDList {
+		rows = append(rows, []string{cid})
+	}
+	return a.printTable([]string{"CID"}, rows)
+}
+
+// runQuota 执行quota命令
+func runQuota(a *app, args []string) error {
+	fs := a.newFlagSet("quota", "quota")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	resp, err := a.client.Advanced.GetVendorQuota()
+	if err != nil {
+		return err
+	}
+	if a.format == "json" {
+		return a.printJSON(resp)
+	}
+
+	var rows [][]string
+	add := func(vendor, kind string, q *goserversdk.QuotaInfo) {
+		if q != nil {
+			rows = append(rows, []string{vendor, kind, quotaValue(q.Total), quotaValue(q.Used)})
+		}
+	}
+	if data := resp.Data; data != nil {
+		if data.XiaomiQuota != nil {
+			add("xiaomi", "operation", data.XiaomiQuota.Operation)
+		}
+		if data.OppoQuota != nil {
+			add("oppo", "operation", data.OppoQuota.Operation)
+		}
+		if data.VivoQuota != nil {
+			add("vivo", "system", data.VivoQuota.System)
+			add("vivo", "operation", data.VivoQuota.Operation)
+		}
+	}
+	return a.printTable([]string{"VENDOR", "TYPE", "TOTAL", "USED"}, rows)
+}
+
+// quotaValue 格式化配额，-1表示不限量
+func quotaValue(n int) string {
+	if n == -1 {
+		return "unlimited"
+	}
+	return strconv.Itoa(n)
+}
+
+// printPushResponse 输出推送响应
+func (a *app) printPushResponse(resp *goserversdk.PushResponse) error {
+	if a.format == "json" {
+		return a.printJSON(map[string]interface{}{"sendno": resp.SendNo, "msg_id": resp.MsgID, "dry_run": resp.DryRun})
+	}
+	return a.printTable([]string{"SENDNO", "MSG_ID", "DRY_RUN"}, [][]string{{resp.SendNo, resp.MsgID, strconv.FormatBool(resp.DryRun)}})
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+)
+
+// runReport 执行report命令
+func runReport(a *app, args []string) error {
+	fs := a.newFlagSet("report", "report received|detail|status [参数]")
+	if len(args) == 0 {
+		return a.usageError(fs, "需要指定子命令：received、detail、status")
+	}
+	switch args[0] {
+	case "received":
+		return runReportReceived(a, args[1:])
+	case "detail":
+		return runReportDetail(a, args[1:])
+	case "status":
+		return runReportStatus(a, args[1:])
+	default:
+		return a.usageError(fs, "未知子命令 %q", args[0])
+	}
+}
+
+// reportMsgIDs 解析位置参数中的消息ID，支持逗号分隔
+func reportMsgIDs(a *app, name string, args []string) ([]string, error) {
+	fs := a.newFlagSet("report "+name, "report "+name+" <msg_id>[,<msg_id>...] ...")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, arg := range fs.Args() {
+		ids = append(ids, splitList(arg)...)
+	}
+	if len(ids) == 0 {
+		return nil, a.usageError(fs, "需要指定msg_id")
+	}
+	return ids, nil
+}
+
+// runReportReceived 查询送达统计详情
+func runReportReceived(a *app, args []string) error {
+	ids, err := reportMsgIDs(a, "received", args)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Report.GetReceivedDetailChunked(ids, nil)
+	chunkErr, err := chunkedError(err)
+	if err != nil {
+		return err
+	}
+	if a.format == "json" {
+		return finishChunked(a.printJSON(resp), chunkErr)
+	}
+
+	rows := make([][]string, 0, len(resp))
+	for _, r := range resp {
+		rows = append(rows, []string{
+			r.MsgID,
+			count(r.JPushReceived),
+			count(r.AndroidPNSSent),
+			count(r.AndroidPNSReceived),
+			count(r.IOSAPNSSent),
+			count(r.IOSAPNSReceived),
+			count(r.HMOSHMPNSReceived),
+		})
+	}
+	return finishChunked(a.printTable([]string{"MSG_ID", "JPUSH_RECEIVED", "ANDROID_PNS_SENT", "ANDROID_PNS_RECEIVED", "IOS_APNS_SENT", "IOS_APNS_RECEIVED", "HMOS_RECEIVED"}, rows), chunkErr)
+}
+
+// runReportDetail 查询消息统计详情
+func runReportDetail(a *app, args []string) error {
+	ids, err := reportMsgIDs(a, "detail", args)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Report.GetMessageDetailChunked(ids, nil)
+	chunkErr, err := chunkedError(err)
+	if err != nil {
+		return err
+	}
+	if a.format == "json" {
+		return finishChunked(a.printJSON(resp), chunkErr)
+	}
+
+	var rows [][]string
+	for _, r := range resp {
+		if r.Details == nil {
+			rows = append(rows, []string{r.MsgID, "-", "-", "-", "-", "-", "-"})
+			continue
+		}
+		if n := r.Details.Notification; n != nil {
+			rows = append(rows, statsRow(r.MsgID, "notification", n.Target, n.Sent, n.Received, n.Display, n.Click))
+		}
+		if m := r.Details.Message; m != nil {
+			rows = append(rows, statsRow(r.MsgID, "message", m.Target, m.Sent, m.Received, m.Display, m.Click))
+		}
+		if i := r.Details.InApp; i != nil {
+			rows = append(rows, statsRow(r.MsgID, "inapp", i.Target, i.Sent, i.Received, i.Display, i.Click))
+		}
+	}
+	return finishChunked(a.printTable([]string{"MSG_ID", "TYPE", "TARGET", "SENT", "RECEIVED", "DISPLAY", "CLICK"}, rows), chunkErr)
+}
+
+// runReportStatus 查询设备的送达状态
+func runReportStatus(a *app, args []string) error {
+	fs := a.newFlagSet("report status", "report status -msg-id <msg_id> -rid <id>[,<id>...] [-date yyyy-mm-dd]")
+	msgID := fs.String("msg-id", "", "消息ID")
+	rid := fs.String("rid", "", "设备注册ID，逗号分隔")
+	date := fs.String("date", "", "查询日期，格式yyyy-mm-dd，默认当天")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	regIDs := splitList(*rid)
+	if *msgID == "" || len(regIDs) == 0 {
+		return a.usageError(fs, "需要指定-msg-id和-rid")
+	}
+
+	resp, err := a.client.Report.GetMessageStatusChunked(&goserversdk.MessageStatusRequest{
+		MsgID:           *msgID,
+		RegistrationIDs: regIDs,
+		Date:            *date,
+	}, nil)
+	chunkErr, err := chunkedError(err)
+	if err != nil {
+		return err
+	}
+	if a.format == "json" {
+		return finishChunked(a.printJSON(resp), chunkErr)
+	}
+
+	rows := make([][]string, 0, len(regIDs))
+	for _, id := range regIDs {
+		status, ok := resp[id]
+		if !ok {
+			rows = append(rows, []string{id, "-"})
+			continue
+		}
+		rows = append(rows, []string{id, status.Status.String()})
+	}
+	return finishChunked(a.printTable([]string{"REGISTRATION_ID", "STATUS"}, rows), chunkErr)
+}
+
+// chunkedError 区分部分批次失败与整体失败，部分失败时返回*ChunkedError以便先输出成功的结果
+func chunkedError(err error) (*goserversdk.ChunkedError, error) {
+	var chunkErr *goserversdk.ChunkedError
+	if err == nil || errors.As(err, &chunkErr) {
+		return chunkErr, nil
+	}
+	return nil, err
+}
+
+// finishChunked 输出结果后报告失败批次中的ID，使命令以非零状态退出
+func finishChunked(printErr error, chunkErr *goserversdk.ChunkedError) error {
+	if printErr != nil {
+		return printErr
+	}
+	if chunkErr == nil {
+		return nil
+	}
+	return fmt.Errorf("部分查询失败，失败的ID: %s: %w", strings.Join(chunkErr.FailedIDs(), ","), chunkErr)
+}
+
+// count 格式化可能为空的统计值
+func count(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return strconv.Itoa(*n)
+}
+
+// statsRow 构造消息统计表格行
+func statsRow(msgID, kind string, values ...int) []string {
+	row := []string{msgID, kind}
+	for _, v := range values {
+		row = append(row, fmt.Sprint(v))
+	}
+	return row
+}
@@ -0,0 +1,106 @@
+package goserversdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// DryRunMode 试运行模式
+type DryRunMode int
+
+const (
+	DryRunOff      DryRunMode = iota // 关闭试运行，正常推送（默认）
+	DryRunValidate                   // 推送请求改发到/v3/push/validate，由JPush校验但不下发
+	DryRunLocal                      // 只在本地校验，不访问JPush
+)
+
+// String 返回模式名称
+func (m DryRunMode) String() string {
+	switch m {
+	case DryRunOff:
+		return "off"
+	case DryRunValidate:
+		return "validate"
+	case DryRunLocal:
+		return "local"
+	default:
+		return fmt.Sprintf("DryRunMode(%d)", int(m))
+	}
+}
+
+// DryRunMsgID 试运行响应中的消息ID，与/v3/push/validate返回的值一致
+const DryRunMsgID = "0"
+
+// maxPushPayloadSize 通知和自定义消息合计的最大字节数
+const maxPushPayloadSize = 4000
+
+// DryRun 返回客户端的试运行模式
+func (c *Client) DryRun() DryRunMode {
+	return c.dryRun
+}
+
+// dryRunPush 以试运行方式处理推送请求，返回DryRun为true的合成响应
+// DryRunValidate模式下remote为false的请求（如文件推送，校验接口不支持）退化为本地校验
+func (c *Client) dryRunPush(ctx context.Context, logger Logger, path string, req interface{}, notification *Notification, message *Message, remote bool) (*PushResponse, error) {
+	mode := c.dryRun
+	if mode == DryRunValidate && !remote {
+		mode = DryRunLocal
+	}
+
+	if logEnabled(logger, LogLevelInfo) {
+		logger.Info("试运行模式，推送未实际发送",
+			"mode", mode.String(),
+			"endpoint", path,
+			"request", c.redactor.value(req))
+	}
+
+	if mode == DryRunLocal {
+		if err := validatePushPayloadSize(notification, message); err != nil {
+			logger.Error("试运行本地校验失败", "error", err)
+			return nil, err
+		}
+		seq := atomic.AddInt64(&c.dryRunSeq, 1)
+		return &PushResponse{SendNo: strconv.FormatInt(seq, 10), MsgID: DryRunMsgID, DryRun: true}, nil
+	}
+
+	resp, err := c.makePushRequestContext(ctx, http.MethodPost, "/v3/push/validate", req)
+	if err != nil {
+		logger.Error("试运行校验请求失败", "error", err)
+		return nil, err
+	}
+
+	var pushResp PushResponse
+	if err := resp.Decode(&pushResp); err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidJSON, "响应解析失败", err)
+	}
+	pushResp.MsgID = DryRunMsgID
+	pushResp.DryRun = true
+	return &pushResp, nil
+}
+
+// validatePushPayloadSize 校验通知和自定义消息合计不超过4000字节
+func validatePushPayloadSize(notification *Notification, message *Message) error {
+	size := 0
+	if notification != nil {
+		data, err := json.Marshal(notification)
+		if err != nil {
+			return wrapJPushError(ErrorCodeInvalidJSON, "通知序列化失败", err)
+		}
+		size += len(data)
+	}
+	if message != nil {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return wrapJPushError(ErrorCodeInvalidJSON, "自定义消息序列化失败", err)
+		}
+		size += len(data)
+	}
+	if size > maxPushPayloadSize {
+		return NewJPushError(ErrorCodeMessageTooLarge, fmt.Sprintf("通知和自定义消息合计%d字节，超过%d字节限制", size, maxPushPayloadSize))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package goserversdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDryRunClient(t *testing.T, mode DryRunMode, baseURL string, logger Logger) *Client {
+	t.Helper()
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: baseURL},
+		LogAdapter:   logger,
+		DryRun:       mode,
+	})
+	assert.NoError(t, err)
+	return client
+}
+
+func dryRunPushRequest(alert string) *PushRequest {
+	return &PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewRegistrationIDAudience("reg1"),
+		Notification: &Notification{Alert: alert},
+	}
+}
+
+func TestDryRun_Validate(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte(`{"sendno":"42","msg_id":"0"}`))
+	}))
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	client := newDryRunClient(t, DryRunValidate, server.URL, logger)
+	assert.Equal(t, DryRunValidate, client.DryRun())
+
+	resp, err := client.Push.Push(dryRunPushRequest("hello"))
+	assert.NoError(t, err)
+	assert.True(t, resp.DryRun)
+	assert.Equal(t, "42", resp.SendNo)
+	assert.Equal(t, DryRunMsgID, resp.MsgID)
+	assert.Contains(t, logger.entries, "info:试运行模式，推送未实际发送")
+
+	// 文件推送不支持校验接口，退化为本地校验
+	fileReq := NewFilePushRequest().SetFileAudience("file-1").SetPlatform("all").SetNotification(&Notification{Alert: "hi"})
+	resp, err = client.Advanced.PushByFile(fileReq)
+	assert.NoError(t, err)
+	assert.True(t, resp.DryRun)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"/v3/push/validate"}, paths)
+}
+
+func TestDryRun_Local(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	tracer := NewMemoryTracer()
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: server.URL},
+		DryRun:       DryRunLocal,
+		Tracer:       tracer,
+	})
+	assert.NoError(t, err)
+
+	first, err := client.Push.Push(dryRunPushRequest("hello"))
+	assert.NoError(t, err)
+	second, err := client.Push.Push(dryRunPushRequest("hello"))
+	assert.NoError(t, err)
+	assert.True(t, first.DryRun)
+	assert.Equal(t, "1", first.SendNo)
+	assert.Equal(t, "2", second.SendNo)
+
+	span, ok := tracer.FindSpan("jpush.Push")
+	assert.True(t, ok)
+	assert.Equal(t, "local", span.Attributes[AttrDryRun])
+
+	// 本地校验消息体大小
+	_, err = client.Push.Push(dryRunPushRequest(strings.Repeat("a", maxPushPayloadSize)))
+	assert.Equal(t, ErrorCodeMessageTooLarge, GetErrorCode(err))
+
+	// 参数校验仍然生效
+	_, err = client.Push.Push(&PushRequest{Platform: "all"})
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, hits)
+}
+
+func TestNewClient_InvalidDryRunMode(t *testing.T) {
+	_, err := NewClient(&Config{AppKey: "app-key", MasterSecret: "master-secret", DryRun: DryRunMode(7)})
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+	assert.Contains(t, err.Error(), "DryRunMode(7)")
+}
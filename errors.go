@@ -1,51 +1,301 @@
 package goserversdk
 
-import "fmt"
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
 
 // ErrorCode 定义JPush API错误码
 type ErrorCode int
 
 const (
-	// 通用错误码
-	ErrorCodeSuccess           ErrorCode = 0    // 成功
-	ErrorCodeInvalidParams     ErrorCode = 1000 // 参数错误
-	ErrorCodeMissingAuth       ErrorCode = 1001 // 缺少认证信息
-	ErrorCodeInvalidAuth       ErrorCode = 1002 // 认证信息错误
-	ErrorCodeInvalidAppKey     ErrorCode = 1003 // AppKey错误
-	ErrorCodeInvalidJSON       ErrorCode = 1004 // JSON格式错误
-	ErrorCodeTimeout           ErrorCode = 1005 // 请求超时
-	ErrorCodeInternalError     ErrorCode = 1006 // 内部错误
-	ErrorCodeRateLimitExceeded ErrorCode = 2002 // 频率限制
-	ErrorCodeAppKeyBlacklisted ErrorCode = 2003 // AppKey被加入黑名单
-	ErrorCodeBroadcastLimit    ErrorCode = 2008 // 广播推送频率限制
+	ErrorCodeSuccess ErrorCode = 0 // 成功
+
+	// 推送API通用错误码
+	ErrorCodeInternalError      ErrorCode = 1000 // 系统内部错误，可重试
+	ErrorCodeMethodNotAllowed   ErrorCode = 1001 // 只支持HTTP POST方法
+	ErrorCodeMissingParams      ErrorCode = 1002 // 缺少必须的参数
+	ErrorCodeInvalidParams      ErrorCode = 1003 // 参数值不合法
+	ErrorCodeInvalidAuth        ErrorCode = 1004 // 验证失败
+	ErrorCodeMessageTooLarge    ErrorCode = 1005 // 消息体太大
+	ErrorCodeInvalidAppKey      ErrorCode = 1008 // app_key参数非法
+	ErrorCodeUnsupportedKey     ErrorCode = 1009 // 推送对象中有不支持的key
+	ErrorCodeNoValidTarget      ErrorCode = 1011 // 没有满足条件的推送目标
+	ErrorCodeInvalidContentType ErrorCode = 1013 // content-type只支持application/json
+	ErrorCodeSensitiveContent   ErrorCode = 1014 // 消息内容包含敏感词汇
+	ErrorCodeHTTPSRequired      ErrorCode = 1020 // 只支持HTTPS请求
+	ErrorCodeTimeout            ErrorCode = 1030 // 内部服务超时，可重试
+
+	// 权限与频率相关错误码
+	ErrorCodeRateLimitExceeded ErrorCode = 2002 // API调用频率超出该应用的限制
+	ErrorCodeAppKeyBlacklisted ErrorCode = 2003 // 该应用AppKey已被限制调用API
+	ErrorCodeIPNotAllowed      ErrorCode = 2004 // 无权限执行当前操作，IP不在白名单内
+	ErrorCodeSendLimitExceeded ErrorCode = 2005 // 信息发送量超出合理范围
+	ErrorCodeNotVIP            ErrorCode = 2006 // 非VIP用户，无法使用VIP功能
+	ErrorCodeNoPermission      ErrorCode = 2007 // 无权限调用此接口
+	ErrorCodeBroadcastLimit    ErrorCode = 2008 // 广播推送超出频率限制
 
 	// 推送相关错误码
-	ErrorCodeInvalidPlatform    ErrorCode = 3001 // 无效的平台
-	ErrorCodeInvalidAudience    ErrorCode = 3002 // 无效的推送目标
+	ErrorCodeInvalidPlatform     ErrorCode = 3001 // 无效的平台
+	ErrorCodeInvalidAudience     ErrorCode = 3002 // 无效的推送目标
 	ErrorCodeInvalidNotification ErrorCode = 3003 // 无效的通知内容
-	ErrorCodeInvalidMessage     ErrorCode = 3004 // 无效的消息内容
-	ErrorCodeInvalidOptions     ErrorCode = 3005 // 无效的推送选项
+	ErrorCodeInvalidMessage      ErrorCode = 3004 // 无效的消息内容
+	ErrorCodeInvalidOptions      ErrorCode = 3005 // 无效的推送选项
 
 	// 设备相关错误码
+	ErrorCodeDeviceInternalError   ErrorCode = 7000 // 设备服务内部错误，可重试
 	ErrorCodeInvalidRegistrationID ErrorCode = 7001 // 无效的注册ID
 	ErrorCodeInvalidTag            ErrorCode = 7002 // 无效的标签
 	ErrorCodeInvalidAlias          ErrorCode = 7003 // 无效的别名
 	ErrorCodeTagLimitExceeded      ErrorCode = 7004 // 标签数量超限
-	ErrorCodeAliasLimitExceeded    ErrorCode = 7015 // 别名绑定设备数量超限
 	ErrorCodeIllegalRegistrationID ErrorCode = 7013 // 非法的注册ID
+	ErrorCodeAliasLimitExceeded    ErrorCode = 7015 // 别名绑定设备数量超限
 	ErrorCodeTagOperationFailed    ErrorCode = 7016 // 标签操作失败
+
+	// SDK本地错误码（9xxx），不会由服务端返回
+	ErrorCodeMissingAuth    ErrorCode = 9001 // 缺少认证信息
+	ErrorCodeInvalidJSON    ErrorCode = 9002 // 请求或响应JSON处理失败
+	ErrorCodeNetwork        ErrorCode = 9003 // 网络错误，如DNS解析失败、连接被拒绝或重置
+	ErrorCodeRequestTimeout ErrorCode = 9004 // 请求超时，包括HTTP客户端超时和context截止时间
+	ErrorCodeCanceled       ErrorCode = 9005 // 请求被调用方取消
+	ErrorCodeTLS            ErrorCode = 9006 // TLS握手或证书校验失败
+	ErrorCodeInvalidRequest ErrorCode = 9007 // 无法构建HTTP请求，如请求地址非法
+	ErrorCodeUnknown        ErrorCode = 9008 // 非JPush错误，无法确定错误码，不可重试
+	ErrorCodeMiddleware     ErrorCode = 9009 // 请求被BeforeSend中间件中止，是否可重试取决于中间件
 )
 
+// 本地错误码范围
+const (
+	minLocalErrorCode ErrorCode = 9000
+	maxLocalErrorCode ErrorCode = 9999
+)
+
+// ErrorClass 错误分类，用于决定重试、丢弃或告警
+type ErrorClass int
+
+const (
+	ErrorClassUnknown        ErrorClass = iota // 未知错误
+	ErrorClassTransient                        // 服务端临时故障，可立即重试
+	ErrorClassRateLimited                      // 触发频率限制，等待后可重试
+	ErrorClassQuota                            // 发送量或配额超限
+	ErrorClassAuth                             // 认证或权限错误
+	ErrorClassInvalidTarget                    // 推送目标无效
+	ErrorClassInvalidRequest                   // 请求内容不合法
+	ErrorClassConfig                           // 本地配置错误，如TLS证书或请求地址，需人工处理
+	ErrorClassCanceled                         // 请求被调用方取消
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassTransient:
+		return "transient"
+	case ErrorClassRateLimited:
+		return "rate_limited"
+	case ErrorClassQuota:
+		return "quota"
+	case ErrorClassAuth:
+		return "auth"
+	case ErrorClassInvalidTarget:
+		return "invalid_target"
+	case ErrorClassInvalidRequest:
+		return "invalid_request"
+	case ErrorClassConfig:
+		return "config"
+	case ErrorClassCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// errorCodeInfo 错误码说明及分类
+type errorCodeInfo struct {
+	description string
+	class       ErrorClass
+}
+
+// errorCodeTable 已知错误码的说明及分类
+var errorCodeTable = map[ErrorCode]errorCodeInfo{
+	ErrorCodeSuccess: {"成功", ErrorClassUnknown},
+
+	ErrorCodeInternalError:      {"系统内部错误", ErrorClassTransient},
+	ErrorCodeMethodNotAllowed:   {"只支持HTTP POST方法", ErrorClassInvalidRequest},
+	ErrorCodeMissingParams:      {"缺少必须的参数", ErrorClassInvalidRequest},
+	ErrorCodeInvalidParams:      {"参数值不合法", ErrorClassInvalidRequest},
+	ErrorCodeInvalidAuth:        {"验证失败", ErrorClassAuth},
+	ErrorCodeMessageTooLarge:    {"消息体太大", ErrorClassInvalidRequest},
+	ErrorCodeInvalidAppKey:      {"app_key参数非法", ErrorClassAuth},
+	ErrorCodeUnsupportedKey:     {"推送对象中有不支持的key", ErrorClassInvalidRequest},
+	ErrorCodeNoValidTarget:      {"没有满足条件的推送目标", ErrorClassInvalidTarget},
+	ErrorCodeInvalidContentType: {"content-type只支持application/json", ErrorClassInvalidRequest},
+	ErrorCodeSensitiveContent:   {"消息内容包含敏感词汇", ErrorClassInvalidRequest},
+	ErrorCodeHTTPSRequired:      {"只支持HTTPS请求", ErrorClassInvalidRequest},
+	ErrorCodeTimeout:            {"内部服务超时", ErrorClassTransient},
+
+	ErrorCodeRateLimitExceeded: {"API调用频率超出该应用的限制", ErrorClassRateLimited},
+	ErrorCodeAppKeyBlacklisted: {"该应用AppKey已被限制调用API", ErrorClassAuth},
+	ErrorCodeIPNotAllowed:      {"无权限执行当前操作，IP不在白名单内", ErrorClassAuth},
+	ErrorCodeSendLimitExceeded: {"信息发送量超出合理范围", ErrorClassQuota},
+	ErrorCodeNotVIP:            {"非VIP用户", ErrorClassAuth},
+	ErrorCodeNoPermission:      {"无权限调用此接口", ErrorClassAuth},
+	ErrorCodeBroadcastLimit:    {"广播推送超出频率限制", ErrorClassRateLimited},
+
+	ErrorCodeInvalidPlatform:     {"无效的平台", ErrorClassInvalidRequest},
+	ErrorCodeInvalidAudience:     {"无效的推送目标", ErrorClassInvalidTarget},
+	ErrorCodeInvalidNotification: {"无效的通知内容", ErrorClassInvalidRequest},
+	ErrorCodeInvalidMessage:      {"无效的消息内容", ErrorClassInvalidRequest},
+	ErrorCodeInvalidOptions:      {"无效的推送选项", ErrorClassInvalidRequest},
+
+	ErrorCodeDeviceInternalError:   {"设备服务内部错误", ErrorClassTransient},
+	ErrorCodeInvalidRegistrationID: {"无效的注册ID", ErrorClassInvalidTarget},
+	ErrorCodeInvalidTag:            {"无效的标签", ErrorClassInvalidRequest},
+	ErrorCodeInvalidAlias:          {"无效的别名", ErrorClassInvalidRequest},
+	ErrorCodeTagLimitExceeded:      {"标签数量超限", ErrorClassQuota},
+	ErrorCodeIllegalRegistrationID: {"非法的注册ID", ErrorClassInvalidTarget},
+	ErrorCodeAliasLimitExceeded:    {"别名绑定设备数量超限", ErrorClassQuota},
+	ErrorCodeTagOperationFailed:    {"标签操作失败", ErrorClassTransient},
+
+	ErrorCodeMissingAuth:    {"缺少认证信息", ErrorClassAuth},
+	ErrorCodeInvalidJSON:    {"JSON处理失败", ErrorClassInvalidRequest},
+	ErrorCodeNetwork:        {"网络错误", ErrorClassTransient},
+	ErrorCodeRequestTimeout: {"请求超时", ErrorClassTransient},
+	ErrorCodeCanceled:       {"请求已取消", ErrorClassCanceled},
+	ErrorCodeTLS:            {"TLS握手或证书校验失败", ErrorClassConfig},
+	ErrorCodeInvalidRequest: {"无法构建HTTP请求", ErrorClassConfig},
+	ErrorCodeUnknown:        {"未知错误", ErrorClassUnknown},
+	ErrorCodeMiddleware:     {"请求被中间件中止", ErrorClassUnknown},
+}
+
+// Description 返回错误码的说明，未知错误码返回空字符串
+func (c ErrorCode) Description() string {
+	return errorCodeTable[c].description
+}
+
+// IsLocal 是否为SDK本地错误码，本地错误码表示请求未得到JPush服务端的响应
+func (c ErrorCode) IsLocal() bool {
+	return c >= minLocalErrorCode && c <= maxLocalErrorCode
+}
+
+// Class 返回错误码的分类，未知错误码返回ErrorClassUnknown
+func (c ErrorCode) Class() ErrorClass {
+	return errorCodeTable[c].class
+}
+
+// 预定义的错误值，可配合errors.Is按错误码判断错误类型
+// 例如：errors.Is(err, ErrRateLimited)
+var (
+	ErrInvalidParams     = &JPushError{Code: ErrorCodeInvalidParams, Message: "参数错误"}
+	ErrMissingAuth       = &JPushError{Code: ErrorCodeMissingAuth, Message: "缺少认证信息"}
+	ErrUnauthorized      = &JPushError{Code: ErrorCodeInvalidAuth, Message: "认证信息错误"}
+	ErrInvalidJSON       = &JPushError{Code: ErrorCodeInvalidJSON, Message: "JSON格式错误"}
+	ErrTimeout           = &JPushError{Code: ErrorCodeTimeout, Message: "内部服务超时"}
+	ErrNetwork           = &JPushError{Code: ErrorCodeNetwork, Message: "网络错误"}
+	ErrRequestTimeout    = &JPushError{Code: ErrorCodeRequestTimeout, Message: "请求超时"}
+	ErrCanceled          = &JPushError{Code: ErrorCodeCanceled, Message: "请求已取消"}
+	ErrTLS               = &JPushError{Code: ErrorCodeTLS, Message: "TLS握手或证书校验失败"}
+	ErrInternal          = &JPushError{Code: ErrorCodeInternalError, Message: "内部错误"}
+	ErrRateLimited       = &JPushError{Code: ErrorCodeRateLimitExceeded, Message: "频率限制"}
+	ErrAppKeyBlacklisted = &JPushError{Code: ErrorCodeAppKeyBlacklisted, Message: "AppKey被加入黑名单"}
+)
+
+// maxErrorBodySnippet 错误中保留的响应体最大字节数
+const maxErrorBodySnippet = 512
+
+// RateLimitInfo 频率限制信息，对应响应头X-Rate-Limit-*
+type RateLimitInfo struct {
+	Limit     int `json:"limit"`     // 周期内允许的请求次数
+	Remaining int `json:"remaining"` // 周期内剩余的请求次数
+	Reset     int `json:"reset"`     // 距离周期重置的秒数
+}
+
 // JPushError JPush API错误
 type JPushError struct {
-	Code    ErrorCode `json:"code"`
-	Message string    `json:"message"`
+	Code       ErrorCode      `json:"code"`
+	Message    string         `json:"message"`
+	HTTPStatus int            `json:"-"` // HTTP状态码，请求未收到响应时为0
+	Path       string         `json:"-"` // 请求路径
+	Body       string         `json:"-"` // 响应体片段，按Redaction策略脱敏，最多保留512字节
+	RateLimit  *RateLimitInfo `json:"-"` // 响应中的频率限制信息
+	Cause      error          `json:"-"` // 底层错误，如网络或JSON解析错误
 }
 
 func (e *JPushError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("JPush API Error [%d]: %s: %v", e.Code, e.Message, e.Cause)
+	}
 	return fmt.Sprintf("JPush API Error [%d]: %s", e.Code, e.Message)
 }
 
+// Unwrap 返回底层错误，支持errors.Is/errors.As沿错误链匹配
+func (e *JPushError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 错误码相同的JPushError视为同一类错误，使errors.Is可以匹配预定义的错误值
+func (e *JPushError) Is(target error) bool {
+	t, ok := target.(*JPushError)
+	return ok && t.Code == e.Code
+}
+
+// Class 返回错误分类
+// 未知错误码按HTTP状态码推断：5xx视为临时故障，429视为频率限制
+func (e *JPushError) Class() ErrorClass {
+	if class := e.Code.Class(); class != ErrorClassUnknown {
+		return class
+	}
+	switch {
+	case e.HTTPStatus == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case e.HTTPStatus >= 500:
+		return ErrorClassTransient
+	case e.HTTPStatus == http.StatusUnauthorized || e.HTTPStatus == http.StatusForbidden:
+		return ErrorClassAuth
+	case e.HTTPStatus >= 400:
+		return ErrorClassInvalidRequest
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// IsRetryable 是否可以重试（临时故障立即重试，频率限制需等待重置后重试）
+func (e *JPushError) IsRetryable() bool {
+	class := e.Class()
+	return class == ErrorClassTransient || class == ErrorClassRateLimited
+}
+
+// IsAuthError 是否为认证或权限错误，需要检查AppKey、MasterSecret或应用权限
+func (e *JPushError) IsAuthError() bool {
+	return e.Class() == ErrorClassAuth
+}
+
+// IsQuotaError 是否为频率限制或发送量、配额超限
+func (e *JPushError) IsQuotaError() bool {
+	class := e.Class()
+	return class == ErrorClassRateLimited || class == ErrorClassQuota
+}
+
+// IsInvalidTarget 是否因推送目标无效而失败，如没有满足条件的推送目标
+func (e *JPushError) IsInvalidTarget() bool {
+	return e.Class() == ErrorClassInvalidTarget
+}
+
+// IsPermanent 是否为重试也无法成功的错误
+func (e *JPushError) IsPermanent() bool {
+	class := e.Class()
+	return class != ErrorClassUnknown && class != ErrorClassCanceled && !e.IsRetryable()
+}
+
+// IsLocal 错误是否源自本地（网络、超时、取消、TLS等），而非JPush服务端返回
+func (e *JPushError) IsLocal() bool {
+	return e.Code.IsLocal()
+}
+
 // NewJPushError 创建JPush错误
 func NewJPushError(code ErrorCode, message string) *JPushError {
 	return &JPushError{
@@ -54,18 +304,32 @@ func NewJPushError(code ErrorCode, message string) *JPushError {
 	}
 }
 
-// IsJPushError 判断是否为JPush错误
+// wrapJPushError 创建携带底层错误的JPush错误
+func wrapJPushError(code ErrorCode, message string, cause error) *JPushError {
+	return &JPushError{
+		Code:    code,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+// IsJPushError 判断错误链中是否包含JPush错误
 func IsJPushError(err error) bool {
-	_, ok := err.(*JPushError)
-	return ok
+	var jpushErr *JPushError
+	return errors.As(err, &jpushErr)
 }
 
-// GetErrorCode 获取错误码
+// GetErrorCode 获取错误链中第一个JPush错误的错误码
+// err为nil时返回ErrorCodeSuccess，错误链中没有JPush错误时返回ErrorCodeUnknown
 func GetErrorCode(err error) ErrorCode {
-	if jpushErr, ok := err.(*JPushError); ok {
+	if err == nil {
+		return ErrorCodeSuccess
+	}
+	var jpushErr *JPushError
+	if errors.As(err, &jpushErr) {
 		return jpushErr.Code
 	}
-	return ErrorCodeInternalError
+	return ErrorCodeUnknown
 }
 
 // GetErrorCodeFromHTTPStatus 根据HTTP状态码获取错误码
@@ -81,7 +345,49 @@ func GetErrorCodeFromHTTPStatus(httpStatus int) ErrorCode {
 		return ErrorCodeAppKeyBlacklisted
 	case 429:
 		return ErrorCodeRateLimitExceeded
+	case 504:
+		return ErrorCodeTimeout
 	default:
 		return ErrorCodeInternalError
 	}
-}
\ No newline at end of file
+}
+
+// transportErrorCode 根据net/http返回的错误判断本地错误码及说明
+func transportErrorCode(err error) (ErrorCode, string) {
+	if errors.Is(err, context.Canceled) {
+		return ErrorCodeCanceled, "请求已取消"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCodeRequestTimeout, "请求超时"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorCodeRequestTimeout, "请求超时"
+	}
+
+	var (
+		certErr      *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		unknownCAErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		certInvalid  x509.CertificateInvalidError
+	)
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &unknownCAErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &certInvalid) {
+		return ErrorCodeTLS, "TLS握手或证书校验失败"
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorCodeNetwork, "DNS解析失败"
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorCodeNetwork, "连接被拒绝"
+	}
+	if errors.Is(err, syscall.ECONNRESET) {
+		return ErrorCodeNetwork, "连接被重置"
+	}
+
+	return ErrorCodeNetwork, "网络错误"
+}
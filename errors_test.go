@@ -1,8 +1,14 @@
 package goserversdk
 
 import (
+	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -77,7 +83,12 @@ func TestGetErrorCode(t *testing.T) {
 		{
 			name:         "non-jpush error",
 			err:          fmt.Errorf("regular error"),
-			expectedCode: ErrorCodeInternalError,
+			expectedCode: ErrorCodeUnknown,
+		},
+		{
+			name:         "nil error",
+			err:          nil,
+			expectedCode: ErrorCodeSuccess,
 		},
 	}
 
@@ -151,20 +162,117 @@ func TestGetErrorCodeFromHTTPStatus(t *testing.T) {
 }
 
 func TestErrorConstants(t *testing.T) {
-	// 测试所有错误码常量是否定义正确
-	assert.Equal(t, 1001, ErrorCodeInvalidAuth)
-	assert.Equal(t, 1002, ErrorCodeMissingAuth)
-	assert.Equal(t, 1003, ErrorCodeInvalidParams)
-	assert.Equal(t, 1004, ErrorCodeInvalidAppKey)
-	assert.Equal(t, 1005, ErrorCodeAppKeyBlacklisted)
-	assert.Equal(t, 2002, ErrorCodeRateLimitExceeded)
-	assert.Equal(t, 1011, ErrorCodeInvalidPlatform)
-	assert.Equal(t, 1012, ErrorCodeInvalidAudience)
-	assert.Equal(t, 1013, ErrorCodeInvalidNotification)
-	assert.Equal(t, 1014, ErrorCodeInvalidMessage)
-	assert.Equal(t, 1015, ErrorCodeInvalidJSON)
-	assert.Equal(t, 5000, ErrorCodeInternalError)
-	assert.Equal(t, 5001, ErrorCodeTimeout)
+	// 测试错误码常量与JPush服务端错误码一致
+	assert.Equal(t, ErrorCode(1000), ErrorCodeInternalError)
+	assert.Equal(t, ErrorCode(1002), ErrorCodeMissingParams)
+	assert.Equal(t, ErrorCode(1003), ErrorCodeInvalidParams)
+	assert.Equal(t, ErrorCode(1004), ErrorCodeInvalidAuth)
+	assert.Equal(t, ErrorCode(1008), ErrorCodeInvalidAppKey)
+	assert.Equal(t, ErrorCode(1011), ErrorCodeNoValidTarget)
+	assert.Equal(t, ErrorCode(1030), ErrorCodeTimeout)
+	assert.Equal(t, ErrorCode(2002), ErrorCodeRateLimitExceeded)
+	assert.Equal(t, ErrorCode(2003), ErrorCodeAppKeyBlacklisted)
+	assert.Equal(t, ErrorCode(2008), ErrorCodeBroadcastLimit)
+	assert.Equal(t, ErrorCode(3002), ErrorCodeInvalidAudience)
+	assert.Equal(t, ErrorCode(7000), ErrorCodeDeviceInternalError)
+
+	// 每个错误码都应有说明
+	for code, info := range errorCodeTable {
+		assert.NotEmpty(t, info.description, "error code %d", code)
+	}
+}
+
+func TestJPushError_Classification(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           *JPushError
+		class         ErrorClass
+		retryable     bool
+		auth          bool
+		quota         bool
+		invalidTarget bool
+		permanent     bool
+	}{
+		{
+			name:      "internal error",
+			err:       NewJPushError(ErrorCodeInternalError, "test"),
+			class:     ErrorClassTransient,
+			retryable: true,
+		},
+		{
+			name:      "service timeout",
+			err:       NewJPushError(ErrorCodeTimeout, "test"),
+			class:     ErrorClassTransient,
+			retryable: true,
+		},
+		{
+			name:      "rate limited",
+			err:       NewJPushError(ErrorCodeRateLimitExceeded, "test"),
+			class:     ErrorClassRateLimited,
+			retryable: true,
+			quota:     true,
+		},
+		{
+			name:      "send limit exceeded",
+			err:       NewJPushError(ErrorCodeSendLimitExceeded, "test"),
+			class:     ErrorClassQuota,
+			quota:     true,
+			permanent: true,
+		},
+		{
+			name:      "auth failed",
+			err:       NewJPushError(ErrorCodeInvalidAuth, "test"),
+			class:     ErrorClassAuth,
+			auth:      true,
+			permanent: true,
+		},
+		{
+			name:          "no valid target",
+			err:           NewJPushError(ErrorCodeNoValidTarget, "test"),
+			class:         ErrorClassInvalidTarget,
+			invalidTarget: true,
+			permanent:     true,
+		},
+		{
+			name:      "invalid params",
+			err:       NewJPushError(ErrorCodeInvalidParams, "test"),
+			class:     ErrorClassInvalidRequest,
+			permanent: true,
+		},
+		{
+			name:      "unknown code with 503",
+			err:       &JPushError{Code: ErrorCode(1999), HTTPStatus: http.StatusServiceUnavailable},
+			class:     ErrorClassTransient,
+			retryable: true,
+		},
+		{
+			name:  "unknown code without response",
+			err:   NewJPushError(ErrorCode(1999), "test"),
+			class: ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.class, tt.err.Class())
+			assert.Equal(t, tt.retryable, tt.err.IsRetryable())
+			assert.Equal(t, tt.auth, tt.err.IsAuthError())
+			assert.Equal(t, tt.quota, tt.err.IsQuotaError())
+			assert.Equal(t, tt.invalidTarget, tt.err.IsInvalidTarget())
+			assert.Equal(t, tt.permanent, tt.err.IsPermanent())
+		})
+	}
+}
+
+func TestErrorCode_Description(t *testing.T) {
+	assert.Equal(t, "没有满足条件的推送目标", ErrorCodeNoValidTarget.Description())
+	assert.Empty(t, ErrorCode(1999).Description())
+	assert.Equal(t, "rate_limited", ErrorCodeRateLimitExceeded.Class().String())
+
+	// 非JPush错误的错误码是本地错误且不可重试
+	unknown := NewJPushError(GetErrorCode(errors.New("local failure")), "local")
+	assert.True(t, unknown.IsLocal())
+	assert.False(t, unknown.IsRetryable())
 }
 
 func TestJPushError_IsType(t *testing.T) {
@@ -179,4 +287,60 @@ func TestJPushError_IsType(t *testing.T) {
 	// 测试错误码匹配
 	assert.Equal(t, ErrorCodeInvalidParams, jpushErr.Code)
 	assert.NotEqual(t, ErrorCodeInvalidAuth, jpushErr.Code)
-}
\ No newline at end of file
+}
+
+func TestJPushError_ErrorsIs(t *testing.T) {
+	err := NewJPushError(ErrorCodeRateLimitExceeded, "too many requests")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.False(t, errors.Is(err, ErrUnauthorized))
+
+	// 包装后的错误仍可匹配
+	wrapped := fmt.Errorf("push failed: %w", err)
+	assert.True(t, errors.Is(wrapped, ErrRateLimited))
+	assert.True(t, IsJPushError(wrapped))
+	assert.Equal(t, ErrorCodeRateLimitExceeded, GetErrorCode(wrapped))
+
+	var jpushErr *JPushError
+	assert.True(t, errors.As(wrapped, &jpushErr))
+	assert.Equal(t, "too many requests", jpushErr.Message)
+}
+
+func TestJPushError_Unwrap(t *testing.T) {
+	cause := io.ErrUnexpectedEOF
+	err := wrapJPushError(ErrorCodeInvalidJSON, "响应体解析失败", cause)
+
+	assert.Equal(t, cause, err.Unwrap())
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.True(t, errors.Is(err, ErrInvalidJSON))
+	assert.Contains(t, err.Error(), cause.Error())
+
+	assert.Nil(t, NewJPushError(ErrorCodeInvalidParams, "test").Unwrap())
+}
+
+func TestTransportErrorCode(t *testing.T) {
+	code, _ := transportErrorCode(&url.Error{Op: "Get", URL: "https://api.jpush.cn", Err: context.DeadlineExceeded})
+	assert.Equal(t, ErrorCodeRequestTimeout, code)
+
+	code, _ = transportErrorCode(&url.Error{Op: "Get", URL: "https://api.jpush.cn", Err: context.Canceled})
+	assert.Equal(t, ErrorCodeCanceled, code)
+
+	code, message := transportErrorCode(&url.Error{Op: "Get", URL: "https://api.jpush.cn", Err: &net.DNSError{Err: "no such host", Name: "api.jpush.cn"}})
+	assert.Equal(t, ErrorCodeNetwork, code)
+	assert.Equal(t, "DNS解析失败", message)
+
+	code, _ = transportErrorCode(&url.Error{Op: "Get", URL: "https://api.jpush.cn", Err: x509.UnknownAuthorityError{}})
+	assert.Equal(t, ErrorCodeTLS, code)
+}
+
+func TestErrorCode_IsLocal(t *testing.T) {
+	assert.True(t, ErrorCodeNetwork.IsLocal())
+	assert.True(t, ErrorCodeRequestTimeout.IsLocal())
+	assert.False(t, ErrorCodeTimeout.IsLocal())
+	assert.False(t, ErrorCodeInternalError.IsLocal())
+
+	canceled := NewJPushError(ErrorCodeCanceled, "canceled")
+	assert.False(t, canceled.IsRetryable())
+	assert.False(t, canceled.IsPermanent())
+	assert.True(t, NewJPushError(ErrorCodeNetwork, "network").IsRetryable())
+	assert.True(t, NewJPushError(ErrorCodeTLS, "tls").IsPermanent())
+}
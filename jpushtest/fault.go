@@ -0,0 +1,97 @@
+package jpushtest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+)
+
+// Fault 注入的故障
+// 请求方法和路径前缀匹配时生效；Status为0时仅注入延迟，之后继续正常处理请求
+type Fault struct {
+	Method     string                // 匹配的请求方法，为空匹配所有方法
+	Path       string                // 匹配的路径前缀，为空匹配所有路径
+	Status     int                   // 响应状态码，如500、503、429
+	Code       goserversdk.ErrorCode // 响应体中的错误码，为0时按状态码推断
+	Message    string                // 响应体中的错误信息
+	Latency    time.Duration         // 响应前的延迟
+	RetryAfter time.Duration         // 429响应的X-Rate-Limit-Reset秒数
+	Times      int                   // 生效次数，小于等于0表示一直生效直到ClearFaults
+}
+
+// ServerError 返回n次5xx错误的故障
+func ServerError(path string, status, n int) Fault {
+	return Fault{Path: path, Status: status, Times: n}
+}
+
+// RateLimited 返回n次429错误的故障
+func RateLimited(path string, retryAfter time.Duration, n int) Fault {
+	return Fault{Path: path, Status: http.StatusTooManyRequests, RetryAfter: retryAfter, Times: n}
+}
+
+// Slow 返回n次响应延迟的故障
+func Slow(path string, latency time.Duration, n int) Fault {
+	return Fault{Path: path, Latency: latency, Times: n}
+}
+
+// InjectFault 注入故障，按注入顺序匹配，每个请求最多触发一个故障
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults 清除所有注入的故障
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// takeFault 取出与请求匹配的故障并扣减剩余次数，调用方需持有锁
+func (s *Server) takeFault(method, path string) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && !strings.EqualFold(f.Method, method) {
+			continue
+		}
+		if !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// write 写出故障响应
+func (f *Fault) write(w http.ResponseWriter) {
+	code := f.Code
+	if code == 0 {
+		switch {
+		case f.Status == http.StatusTooManyRequests:
+			code = goserversdk.ErrorCodeRateLimitExceeded
+		case f.Status >= 500:
+			code = goserversdk.ErrorCodeInternalError
+		default:
+			code = goserversdk.ErrorCodeInvalidParams
+		}
+	}
+	message := f.Message
+	if message == "" {
+		message = http.StatusText(f.Status)
+	}
+	if f.Status == http.StatusTooManyRequests {
+		w.Header().Set("X-Rate-Limit-Limit", "0")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", strconv.Itoa(int(f.RetryAfter/time.Second)))
+	}
+	writeError(w, f.Status, code, message)
+}
@@ -0,0 +1,585 @@
+package jpushtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+)
+
+// 与真实接口一致的限制
+const (
+	maxPayloadSize = 4000 // 通知和自定义消息合计的最大字节数
+	maxCIDCount    = 1000 // 单次获取CID的最大数量
+)
+
+// Device 模拟服务中注册的设备，用于解析推送目标
+type Device struct {
+	RegistrationID string   // 设备注册ID
+	Platform       string   // 平台，如android、ios、hmos，为空匹配所有平台
+	Alias          string   // 别名
+	Tags           []string // 标签
+	Mobile         string   // 手机号
+}
+
+// hasTag 判断设备是否有指定标签
+func (d *Device) hasTag(tag string) bool {
+	for _, t := range d.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Push 模拟服务收到的推送
+type Push struct {
+	MsgID      string                   // 分配的消息ID
+	SendNo     string                   // 推送序号
+	Request    *goserversdk.PushRequest // 解析后的推送请求
+	Body       json.RawMessage          // 原始请求体
+	Platforms  []string                 // 推送平台
+	Targets    []string                 // 解析出的目标设备注册ID
+	Broadcast  bool                     // 是否为广播
+	Cancelled  bool                     // 是否已撤销
+	ReceivedAt time.Time                // 收到时间
+}
+
+// clone 返回推送的快照，避免调用方读取时与撤销等操作产生数据竞争
+// 推送记录后只有Cancelled会被修改，其余字段可以共享
+func (p *Push) clone() *Push {
+	c := *p
+	return &c
+}
+
+// Alert 返回推送到指定平台的通知内容，优先使用平台专属内容
+func (p *Push) Alert(platform string) string {
+	n := p.Request.Notification
+	if n == nil {
+		return ""
+	}
+	switch platform {
+	case goserversdk.PlatformAndroid:
+		if n.Android != nil && n.Android.Alert != "" {
+			return n.Android.Alert
+		}
+	case goserversdk.PlatformIOS:
+		if n.IOS != nil && n.IOS.Alert != nil {
+			switch alert := n.IOS.Alert.(type) {
+			case string:
+				if alert != "" {
+					return alert
+				}
+			case map[string]interface{}:
+				if body, ok := alert["body"].(string); ok {
+					return body
+				}
+			}
+		}
+	case goserversdk.PlatformHMOS:
+		if n.HMOS != nil && n.HMOS.Alert != "" {
+			return n.HMOS.Alert
+		}
+	case goserversdk.PlatformQuickApp:
+		if n.QuickApp != nil && n.QuickApp.Alert != "" {
+			return n.QuickApp.Alert
+		}
+	}
+	return n.Alert
+}
+
+// MessageContent 返回自定义消息内容
+func (p *Push) MessageContent() string {
+	if p.Request.Message == nil {
+		return ""
+	}
+	return p.Request.Message.MsgContent
+}
+
+// platforms 返回推送平台，platform为all时返回全部平台
+func (p *Push) platforms() []string {
+	if len(p.Platforms) > 0 {
+		return p.Platforms
+	}
+	return []string{goserversdk.PlatformAndroid, goserversdk.PlatformIOS, goserversdk.PlatformHMOS, goserversdk.PlatformQuickApp}
+}
+
+// targets 判断推送是否发给了指定设备
+func (p *Push) targets(regID string) bool {
+	for _, id := range p.Targets {
+		if id == regID {
+			return true
+		}
+	}
+	return false
+}
+
+// pushRequestJSON 推送请求的接收格式
+type pushRequestJSON struct {
+	Platform     json.RawMessage           `json:"platform"`
+	Audience     json.RawMessage           `json:"audience"`
+	Notification *goserversdk.Notification `json:"notification"`
+	Message      *goserversdk.Message      `json:"message"`
+	SMSMessage   *goserversdk.SMSMessage   `json:"sms_message"`
+	Options      *goserversdk.Options      `json:"options"`
+	Callback     *goserversdk.Callback     `json:"callback"`
+	CID          *string                   `json:"cid"`
+}
+
+// apiError 模拟接口的错误
+type apiError struct {
+	status  int
+	code    goserversdk.ErrorCode
+	message string
+}
+
+func newAPIError(code goserversdk.ErrorCode, format string, args ...interface{}) *apiError {
+	return &apiError{status: http.StatusBadRequest, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// AddDevice 注册设备，已存在时覆盖
+func (s *Server) AddDevice(devices ...Device) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range devices {
+		d := d
+		d.Tags = append([]string(nil), d.Tags...)
+		s.devices[d.RegistrationID] = &d
+	}
+}
+
+// Device 返回已注册的设备
+func (s *Server) Device(regID string) (Device, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.devices[regID]
+	if !ok {
+		return Device{}, false
+	}
+	return *d, true
+}
+
+// Pushes 返回收到的全部推送的快照，不含校验接口的请求
+func (s *Server) Pushes() []*Push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]*Push, 0, len(s.pushes))
+	for _, p := range s.pushes {
+		result = append(result, p.clone())
+	}
+	return result
+}
+
+// LastPush 返回最近一次推送的快照，没有推送时返回nil
+func (s *Server) LastPush() *Push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pushes) == 0 {
+		return nil
+	}
+	return s.pushes[len(s.pushes)-1].clone()
+}
+
+// PushByID 按消息ID查找推送，返回快照
+func (s *Server) PushByID(msgID string) *Push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := s.findPush(msgID); p != nil {
+		return p.clone()
+	}
+	return nil
+}
+
+// findPush 按消息ID查找推送，调用方需持有锁
+func (s *Server) findPush(msgID string) *Push {
+	for _, p := range s.pushes {
+		if p.MsgID == msgID {
+			return p
+		}
+	}
+	return nil
+}
+
+// ReceivedBy 返回指定设备收到的未撤销推送，target可以是注册ID或别名
+func (s *Server) ReceivedBy(target string) []*Push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	regIDs := s.lookupTarget(target)
+	var result []*Push
+	for _, p := range s.pushes {
+		if p.Cancelled {
+			continue
+		}
+		for _, id := range regIDs {
+			if p.targets(id) {
+				result = append(result, p.clone())
+				break
+			}
+		}
+	}
+	return result
+}
+
+// HasReceived 判断指定设备是否收到通知内容或自定义消息内容为content的推送
+func (s *Server) HasReceived(target, content string) bool {
+	for _, p := range s.ReceivedBy(target) {
+		if p.MessageContent() == content {
+			return true
+		}
+		for _, platform := range p.platforms() {
+			if p.Alert(platform) == content {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AssertReceived 断言指定设备收到内容为content的推送
+func (s *Server) AssertReceived(t testing.TB, target, content string) {
+	t.Helper()
+	if !s.HasReceived(target, content) {
+		t.Errorf("jpushtest: %q did not receive %q; received %s", target, content, s.describeReceived(target))
+	}
+}
+
+// AssertNotReceived 断言指定设备没有收到内容为content的推送
+func (s *Server) AssertNotReceived(t testing.TB, target, content string) {
+	t.Helper()
+	if s.HasReceived(target, content) {
+		t.Errorf("jpushtest: %q unexpectedly received %q", target, content)
+	}
+}
+
+// describeReceived 描述设备收到的推送，用于断言失败信息
+func (s *Server) describeReceived(target string) string {
+	pushes := s.ReceivedBy(target)
+	if len(pushes) == 0 {
+		return "nothing"
+	}
+	parts := make([]string, 0, len(pushes))
+	for _, p := range pushes {
+		content := p.MessageContent()
+		if alert := p.Alert(p.platforms()[0]); alert != "" {
+			content = alert
+		}
+		parts = append(parts, fmt.Sprintf("%s:%q", p.MsgID, content))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lookupTarget 将注册ID或别名解析为注册ID列表，调用方需持有锁
+func (s *Server) lookupTarget(target string) []string {
+	if _, ok := s.devices[target]; ok {
+		return []string{target}
+	}
+	var ids []string
+	for id, d := range s.devices {
+		if d.Alias == target {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		// 未注册的注册ID按自身处理
+		ids = []string{target}
+	}
+	return ids
+}
+
+// handlePush 处理推送和推送校验请求
+func (s *Server) handlePush(w http.ResponseWriter, body []byte, validateOnly bool) {
+	req, platforms, broadcast, audience, apiErr := parsePushRequest(body)
+	if apiErr != nil {
+		writeError(w, apiErr.status, apiErr.code, apiErr.message)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	targets := s.resolveAudience(platforms, broadcast, audience)
+	if len(targets) == 0 {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeNoValidTarget, "cannot find user by this audience or has been inactive for more than 255 days")
+		return
+	}
+
+	s.nextSendNo++
+	resp := map[string]string{"sendno": strconv.FormatInt(s.nextSendNo, 10), "msg_id": "0"}
+	if validateOnly {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	s.nextMsgID++
+	push := &Push{
+		MsgID:      strconv.FormatInt(s.nextMsgID, 10),
+		SendNo:     resp["sendno"],
+		Request:    req,
+		Body:       append(json.RawMessage(nil), body...),
+		Platforms:  platforms,
+		Targets:    targets,
+		Broadcast:  broadcast,
+		ReceivedAt: s.now(),
+	}
+	s.pushes = append(s.pushes, push)
+	resp["msg_id"] = push.MsgID
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parsePushRequest 按真实接口的规则解析和校验推送请求
+func parsePushRequest(body []byte) (*goserversdk.PushRequest, []string, bool, *goserversdk.Audience, *apiError) {
+	var raw pushRequestJSON
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeInvalidParams, "invalid json: %v", err)
+	}
+	if len(raw.Platform) == 0 || string(raw.Platform) == "null" {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeMissingParams, "platform is required")
+	}
+	if len(raw.Audience) == 0 || string(raw.Audience) == "null" {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeMissingParams, "audience is required")
+	}
+
+	platforms, err := parsePlatform(raw.Platform)
+	if err != nil {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeInvalidPlatform, "%v", err)
+	}
+	broadcast, audience, err := parseAudience(raw.Audience)
+	if err != nil {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeInvalidAudience, "%v", err)
+	}
+
+	if raw.Notification == nil && raw.Message == nil {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeInvalidParams, "notification or message is required")
+	}
+	if raw.Message != nil && raw.Message.MsgContent == "" {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeInvalidParams, "message.msg_content is required")
+	}
+	if raw.Callback != nil {
+		if err := validateCallback(raw.Callback); err != nil {
+			return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeInvalidParams, "invalid callback: %v", err)
+		}
+	}
+
+	size := 0
+	for _, part := range []interface{}{raw.Notification, raw.Message} {
+		if part == nil {
+			continue
+		}
+		data, _ := json.Marshal(part)
+		size += len(data)
+	}
+	if size > maxPayloadSize {
+		return nil, nil, false, nil, newAPIError(goserversdk.ErrorCodeMessageTooLarge, "notification and message exceed %d bytes", maxPayloadSize)
+	}
+
+	req := &goserversdk.PushRequest{
+		Audience:     audience,
+		Notification: raw.Notification,
+		Message:      raw.Message,
+		SMSMessage:   raw.SMSMessage,
+		Options:      raw.Options,
+		Callback:     raw.Callback,
+		CID:          raw.CID,
+	}
+	if len(platforms) == 0 {
+		req.Platform = "all"
+	} else {
+		req.Platform = platforms
+	}
+	return req, platforms, broadcast, audience, nil
+}
+
+// callbackTypes 推送API文档中callback.type的取值：
+// 1送达回执、2点击回执、3送达和点击回执、8推送成功回执、9成功和送达回执、10成功和点击回执、11成功、送达和点击回执
+var callbackTypes = map[int]bool{1: true, 2: true, 3: true, 8: true, 9: true, 10: true, 11: true}
+
+// validateCallback 按推送API文档校验callback字段
+// url可选，未设置时使用控制台配置的回调地址；type可选，设置时必须是文档列出的取值
+func validateCallback(cb *goserversdk.Callback) error {
+	if cb.URL != "" {
+		u, err := url.Parse(cb.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid url %q", cb.URL)
+		}
+	}
+	if cb.Type != nil && !callbackTypes[*cb.Type] {
+		return fmt.Errorf("invalid type %d", *cb.Type)
+	}
+	return nil
+}
+
+// parsePlatform 解析platform字段，"all"返回nil
+func parsePlatform(data json.RawMessage) ([]string, error) {
+	var all string
+	if err := json.Unmarshal(data, &all); err == nil {
+		if all != "all" {
+			return nil, fmt.Errorf("invalid platform %q", all)
+		}
+		return nil, nil
+	}
+	var platforms []string
+	if err := json.Unmarshal(data, &platforms); err != nil || len(platforms) == 0 {
+		return nil, fmt.Errorf("invalid platform %s", data)
+	}
+	for _, p := range platforms {
+		switch p {
+		case goserversdk.PlatformAndroid, goserversdk.PlatformIOS, goserversdk.PlatformHMOS, goserversdk.PlatformQuickApp, "voip":
+		default:
+			return nil, fmt.Errorf("invalid platform %q", p)
+		}
+	}
+	return platforms, nil
+}
+
+// parseAudience 解析audience字段，与真实接口一致，广播只接受字符串"all"
+func parseAudience(data json.RawMessage) (bool, *goserversdk.Audience, error) {
+	var all string
+	if err := json.Unmarshal(data, &all); err == nil {
+		if all != "all" {
+			return false, nil, fmt.Errorf("invalid audience %q", all)
+		}
+		return true, goserversdk.NewBroadcastAudience(), nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false, nil, fmt.Errorf("invalid audience: %v", err)
+	}
+	if _, ok := fields["all"]; ok {
+		return false, nil, fmt.Errorf("invalid audience key \"all\", broadcast audience must be the string \"all\"")
+	}
+	var audience goserversdk.Audience
+	if err := json.Unmarshal(data, &audience); err != nil {
+		return false, nil, fmt.Errorf("invalid audience: %v", err)
+	}
+	if len(audience.Tag)+len(audience.TagAnd)+len(audience.TagNot)+len(audience.Alias)+
+		len(audience.RegistrationID)+len(audience.Segment)+len(audience.ABTest) == 0 && audience.LiveActivityID == nil {
+		return false, nil, fmt.Errorf("audience is empty")
+	}
+	return false, &audience, nil
+}
+
+// resolveAudience 将推送目标解析为注册ID列表，调用方需持有锁
+// 各类目标之间取交集；registration_id不要求设备已注册
+func (s *Server) resolveAudience(platforms []string, broadcast bool, audience *goserversdk.Audience) []string {
+	platformOK := func(d *Device) bool {
+		if len(platforms) == 0 || d.Platform == "" {
+			return true
+		}
+		for _, p := range platforms {
+			if p == d.Platform {
+				return true
+			}
+		}
+		return false
+	}
+
+	var ids []string
+	if broadcast {
+		for id, d := range s.devices {
+			if platformOK(d) {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if len(audience.RegistrationID) > 0 && len(audience.Tag)+len(audience.TagAnd)+len(audience.TagNot)+len(audience.Alias) == 0 {
+		return append(ids, audience.RegistrationID...)
+	}
+
+	for id, d := range s.devices {
+		if !platformOK(d) || !matchAudience(d, audience) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// matchAudience 判断设备是否满足推送目标的全部条件
+func matchAudience(d *Device, audience *goserversdk.Audience) bool {
+	if len(audience.Tag) > 0 {
+		matched := false
+		for _, tag := range audience.Tag {
+			if d.hasTag(tag) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, tag := range audience.TagAnd {
+		if !d.hasTag(tag) {
+			return false
+		}
+	}
+	for _, tag := range audience.TagNot {
+		if d.hasTag(tag) {
+			return false
+		}
+	}
+	if len(audience.Alias) > 0 && !contains(audience.Alias, d.Alias) {
+		return false
+	}
+	if len(audience.RegistrationID) > 0 && !contains(audience.RegistrationID, d.RegistrationID) {
+		return false
+	}
+	return len(audience.Tag)+len(audience.TagAnd)+len(audience.TagNot)+len(audience.Alias)+len(audience.RegistrationID) > 0
+}
+
+// contains 判断列表中是否包含指定值
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// handleCID 处理获取CID请求
+func (s *Server) handleCID(w http.ResponseWriter, r *http.Request) {
+	count := 1
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxCIDCount {
+			writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "count must be in [1,1000]")
+			return
+		}
+		count = n
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cids := make([]string, count)
+	for i := range cids {
+		s.nextSendNo++
+		cids[i] = fmt.Sprintf("%s-%08d", s.appKey, s.nextSendNo)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cidlist": cids})
+}
+
+// handleCancel 处理推送撤销请求
+func (s *Server) handleCancel(w http.ResponseWriter, msgID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findPush(msgID)
+	if p == nil {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "msg_id not found")
+		return
+	}
+	p.Cancelled = true
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
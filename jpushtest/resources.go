@@ -0,0 +1,367 @@
+package jpushtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+)
+
+// maxReportMsgIDs 统计接口单次查询的最大消息ID数量
+const maxReportMsgIDs = 100
+
+// deviceUpdateJSON 设备更新请求格式
+type deviceUpdateJSON struct {
+	Tags *struct {
+		Add    []string `json:"add"`
+		Remove []string `json:"remove"`
+	} `json:"tags"`
+	Alias  *string `json:"alias"`
+	Mobile *string `json:"mobile"`
+}
+
+// handleDevice 处理设备查询和更新请求
+func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request, regID string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.devices[regID]
+	if !ok {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeIllegalRegistrationID, "illegal registration_id")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		tags := d.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tags": tags, "alias": d.Alias, "mobile": d.Mobile})
+	case http.MethodPost:
+		var update deviceUpdateJSON
+		if err := json.Unmarshal(body, &update); err != nil {
+			writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "invalid json")
+			return
+		}
+		if update.Tags != nil {
+			for _, tag := range update.Tags.Add {
+				if !d.hasTag(tag) {
+					d.Tags = append(d.Tags, tag)
+				}
+			}
+			kept := d.Tags[:0]
+			for _, tag := range d.Tags {
+				if !contains(update.Tags.Remove, tag) {
+					kept = append(kept, tag)
+				}
+			}
+			d.Tags = kept
+		}
+		if update.Alias != nil {
+			d.Alias = *update.Alias
+		}
+		if update.Mobile != nil {
+			d.Mobile = *update.Mobile
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, goserversdk.ErrorCodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleCreateSchedule 处理创建定时任务请求
+func (s *Server) handleCreateSchedule(w http.ResponseWriter, body []byte) {
+	var schedule map[string]json.RawMessage
+	if err := json.Unmarshal(body, &schedule); err != nil {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "invalid json")
+		return
+	}
+	var name string
+	json.Unmarshal(schedule["name"], &name)
+	if name == "" || len(schedule["trigger"]) == 0 || len(schedule["push"]) == 0 {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeMissingParams, "name, trigger and push are required")
+		return
+	}
+	if _, _, _, _, apiErr := parsePushRequest(schedule["push"]); apiErr != nil {
+		writeError(w, apiErr.status, apiErr.code, apiErr.message)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextSchedID++
+	id := "schedule-" + strconv.FormatInt(s.nextSchedID, 10)
+	schedule["schedule_id"], _ = json.Marshal(id)
+	s.schedules[id], _ = json.Marshal(schedule)
+	writeJSON(w, http.StatusOK, map[string]string{"schedule_id": id, "name": name})
+}
+
+// handleSchedule 处理定时任务查询、更新和删除请求
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, ok := s.schedules[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, goserversdk.ErrorCodeInvalidParams, "schedule not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(schedule)
+	case http.MethodDelete:
+		delete(s.schedules, id)
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, goserversdk.ErrorCodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// Schedules 返回已创建的定时任务ID
+func (s *Server) Schedules() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.schedules))
+	for id := range s.schedules {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// reportMsgIDs 解析统计接口的msg_ids参数
+func reportMsgIDs(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	value := r.URL.Query().Get("msg_ids")
+	if value == "" {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeMissingParams, "msg_ids is required")
+		return nil, false
+	}
+	ids := strings.Split(value, ",")
+	if len(ids) > maxReportMsgIDs {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "msg_ids exceeds 100")
+		return nil, false
+	}
+	return ids, true
+}
+
+// deliveryCounts 按平台统计推送的送达数，调用方需持有锁
+// 已撤销的推送送达数为0；iOS设备计入APNs，其余计入极光通道
+func (s *Server) deliveryCounts(p *Push) (jpush, ios int) {
+	if p.Cancelled {
+		return 0, 0
+	}
+	for _, id := range p.Targets {
+		if d, ok := s.devices[id]; ok && d.Platform == goserversdk.PlatformIOS {
+			ios++
+		} else {
+			jpush++
+		}
+	}
+	return jpush, ios
+}
+
+// handleReceivedDetail 处理送达统计详情请求
+func (s *Server) handleReceivedDetail(w http.ResponseWriter, r *http.Request) {
+	ids, ok := reportMsgIDs(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		item := map[string]interface{}{"msg_id": id}
+		if p := s.findPush(id); p != nil {
+			jpush, ios := s.deliveryCounts(p)
+			item["jpush_received"] = jpush
+			item["ios_apns_sent"] = ios
+			item["ios_apns_received"] = ios
+		}
+		result = append(result, item)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleReceived 处理送达统计请求
+func (s *Server) handleReceived(w http.ResponseWriter, r *http.Request) {
+	ids, ok := reportMsgIDs(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		item := map[string]interface{}{"msg_id": id}
+		if p := s.findPush(id); p != nil {
+			jpush, ios := s.deliveryCounts(p)
+			item["android_received"] = jpush
+			item["ios_apns_sent"] = ios
+			item["ios_apns_received"] = ios
+		}
+		result = append(result, item)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleMessageDetail 处理消息统计详情请求
+func (s *Server) handleMessageDetail(w http.ResponseWriter, r *http.Request) {
+	ids, ok := reportMsgIDs(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		item := map[string]interface{}{"msg_id": id}
+		if p := s.findPush(id); p != nil {
+			jpush, ios := s.deliveryCounts(p)
+			stats := map[string]int{"target": len(p.Targets), "sent": jpush + ios, "received": jpush + ios}
+			details := map[string]interface{}{}
+			if p.Request.Notification != nil {
+				details["notification"] = stats
+			}
+			if p.Request.Message != nil {
+				details["message"] = stats
+			}
+			item["details"] = details
+		}
+		result = append(result, item)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleMessages 处理消息统计请求，Android统计包含iOS以外的所有设备
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request) {
+	ids, ok := reportMsgIDs(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		item := map[string]interface{}{"msg_id": id}
+		if p := s.findPush(id); p != nil {
+			var jpushTarget, iosTarget int
+			for _, target := range p.Targets {
+				if d, ok := s.devices[target]; ok && d.Platform == goserversdk.PlatformIOS {
+					iosTarget++
+				} else {
+					jpushTarget++
+				}
+			}
+			jpush, ios := s.deliveryCounts(p)
+			item["android"] = map[string]int{"target": jpushTarget, "received": jpush, "online_push": jpush, "click": 0, "msg_click": 0}
+			item["ios"] = map[string]int{"apns_target": iosTarget, "apns_sent": ios, "apns_received": ios, "click": 0}
+		}
+		result = append(result, item)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// userStatsUnits 用户统计的时间单位：起始时间格式、最大时长和步进
+var userStatsUnits = map[string]struct {
+	layout      string
+	maxDuration int
+	step        func(t time.Time, n int) time.Time
+}{
+	"HOUR":  {"2006-01-02 15", 24, func(t time.Time, n int) time.Time { return t.Add(time.Duration(n) * time.Hour) }},
+	"DAY":   {"2006-01-02", 60, func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) }},
+	"MONTH": {"2006-01", 2, func(t time.Time, n int) time.Time { return t.AddDate(0, n, 0) }},
+}
+
+// handleUsers 处理用户统计请求
+// 每个时间段的在线和活跃用户数为已注册设备数，新增用户数为0；iOS以外的设备计入Android
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	unit, ok := userStatsUnits[query.Get("time_unit")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "invalid time_unit")
+		return
+	}
+	start, err := time.Parse(unit.layout, query.Get("start"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "invalid start")
+		return
+	}
+	duration, err := strconv.Atoi(query.Get("duration"))
+	if err != nil || duration <= 0 || duration > unit.maxDuration {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "invalid duration")
+		return
+	}
+
+	s.mu.Lock()
+	var android, ios int
+	for _, d := range s.devices {
+		if d.Platform == goserversdk.PlatformIOS {
+			ios++
+		} else {
+			android++
+		}
+	}
+	s.mu.Unlock()
+
+	items := make([]map[string]interface{}, 0, duration)
+	for i := 0; i < duration; i++ {
+		items = append(items, map[string]interface{}{
+			"time":    unit.step(start, i).Format(unit.layout),
+			"android": map[string]int{"new": 0, "online": android, "active": android},
+			"ios":     map[string]int{"new": 0, "online": ios, "active": ios},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"time_unit": query.Get("time_unit"),
+		"start":     query.Get("start"),
+		"duration":  duration,
+		"items":     items,
+	})
+}
+
+// messageStatusJSON 送达状态查询请求格式
+type messageStatusJSON struct {
+	MsgID           json.Number `json:"msg_id"`
+	RegistrationIDs []string    `json:"registration_ids"`
+}
+
+// handleMessageStatus 处理送达状态查询请求
+func (s *Server) handleMessageStatus(w http.ResponseWriter, body []byte) {
+	var req messageStatusJSON
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "invalid json")
+		return
+	}
+	if req.MsgID == "" || len(req.RegistrationIDs) == 0 {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeMissingParams, "msg_id and registration_ids are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findPush(req.MsgID.String())
+	result := make(map[string]goserversdk.MessageStatus, len(req.RegistrationIDs))
+	for _, id := range req.RegistrationIDs {
+		_, registered := s.devices[id]
+		status := goserversdk.DeliveryStatusNotTarget
+		switch {
+		case p != nil && p.targets(id) && p.Cancelled:
+			status = goserversdk.DeliveryStatusNotDelivered
+		case p != nil && p.targets(id):
+			status = goserversdk.DeliveryStatusDelivered
+		case !registered:
+			status = goserversdk.DeliveryStatusInvalidRegistrationID
+		}
+		result[id] = goserversdk.MessageStatus{Status: status}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
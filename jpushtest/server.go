@@ -0,0 +1,297 @@
+// Package jpushtest 提供用于集成测试的JPush内存模拟服务
+//
+// Server模拟推送、设备、统计和定时任务接口：记录收到的推送并分配msg_id，
+// 按与真实接口相同的规则校验请求、执行频率限制，并支持注入5xx、429和延迟等故障。
+//
+//	srv := jpushtest.NewServer()
+//	defer srv.Close()
+//	srv.AddDevice(jpushtest.Device{RegistrationID: "reg1", Alias: "alice"})
+//
+//	client, _ := goserversdk.NewClient(srv.Config())
+//	client.Push.Push(req)
+//	srv.AssertReceived(t, "alice", "Hello")
+package jpushtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+)
+
+// 默认认证信息
+const (
+	DefaultAppKey       = "jpushtest-app-key"
+	DefaultMasterSecret = "jpushtest-master-secret"
+)
+
+// 默认频率限制，与JPush免费版一致：每分钟600次
+const (
+	defaultRateLimit  = 600
+	defaultRateWindow = time.Minute
+)
+
+// Option 模拟服务选项
+type Option func(*Server)
+
+// WithCredentials 设置模拟服务接受的AppKey和MasterSecret
+func WithCredentials(appKey, masterSecret string) Option {
+	return func(s *Server) {
+		s.appKey, s.masterSecret = appKey, masterSecret
+	}
+}
+
+// WithRateLimit 设置频率限制，limit小于等于0表示不限制
+func WithRateLimit(limit int, window time.Duration) Option {
+	return func(s *Server) {
+		s.rateLimit, s.rateWindow = limit, window
+	}
+}
+
+// WithClock 设置时间来源，便于测试频率限制窗口
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// Server JPush模拟服务
+type Server struct {
+	srv *httptest.Server
+
+	appKey       string
+	masterSecret string
+	rateLimit    int
+	rateWindow   time.Duration
+	now          func() time.Time
+
+	mu          sync.Mutex
+	nextMsgID   int64
+	nextSendNo  int64
+	pushes      []*Push
+	devices     map[string]*Device
+	schedules   map[string]json.RawMessage
+	nextSchedID int64
+	faults      []*Fault
+	requests    []RecordedRequest
+	windowStart time.Time
+	windowCount int
+}
+
+// RecordedRequest 模拟服务收到的请求
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+// NewServer 创建并启动模拟服务
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		appKey:       DefaultAppKey,
+		masterSecret: DefaultMasterSecret,
+		rateLimit:    defaultRateLimit,
+		rateWindow:   defaultRateWindow,
+		now:          time.Now,
+		nextMsgID:    18100000000000000,
+		devices:      make(map[string]*Device),
+		schedules:    make(map[string]json.RawMessage),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close 关闭模拟服务
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// URL 返回模拟服务地址
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// BaseURLs 返回所有服务均指向模拟服务的域名配置
+func (s *Server) BaseURLs() *goserversdk.BaseURLs {
+	return &goserversdk.BaseURLs{
+		Push:     s.srv.URL,
+		Device:   s.srv.URL,
+		Report:   s.srv.URL,
+		Schedule: s.srv.URL,
+		Admin:    s.srv.URL,
+	}
+}
+
+// Config 返回连接模拟服务的客户端配置
+func (s *Server) Config() *goserversdk.Config {
+	return &goserversdk.Config{
+		AppKey:       s.appKey,
+		MasterSecret: s.masterSecret,
+		BaseURLs:     s.BaseURLs(),
+	}
+}
+
+// Requests 返回收到的全部请求
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// Reset 清除推送、请求、故障和频率限制状态，保留已注册的设备
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pushes = nil
+	s.requests = nil
+	s.faults = nil
+	s.schedules = make(map[string]json.RawMessage)
+	s.windowCount = 0
+}
+
+// serveHTTP 处理请求：记录、故障注入、认证、频率限制，然后分发到具体接口
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, goserversdk.ErrorCodeInvalidParams, "failed to read body")
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: body})
+	fault := s.takeFault(r.Method, r.URL.Path)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Status != 0 {
+			fault.write(w)
+			return
+		}
+	}
+
+	if !s.checkAuth(r) {
+		writeError(w, http.StatusUnauthorized, goserversdk.ErrorCodeInvalidAuth, "Authen failed")
+		return
+	}
+
+	if !s.allow(w) {
+		writeError(w, http.StatusTooManyRequests, goserversdk.ErrorCodeRateLimitExceeded, "Request times is over the limit")
+		return
+	}
+
+	s.route(w, r, body)
+}
+
+// route 按路径分发请求
+func (s *Server) route(w http.ResponseWriter, r *http.Request, body []byte) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/v3/push" && r.Method == http.MethodPost:
+		s.handlePush(w, body, false)
+	case path == "/v3/push/validate" && r.Method == http.MethodPost:
+		s.handlePush(w, body, true)
+	case path == "/v3/push/cid" && r.Method == http.MethodGet:
+		s.handleCID(w, r)
+	case strings.HasPrefix(path, "/v3/push/") && r.Method == http.MethodDelete:
+		s.handleCancel(w, strings.TrimPrefix(path, "/v3/push/"))
+	case strings.HasPrefix(path, "/v3/devices/"):
+		s.handleDevice(w, r, strings.TrimPrefix(path, "/v3/devices/"), body)
+	case path == "/v3/schedules" && r.Method == http.MethodPost:
+		s.handleCreateSchedule(w, body)
+	case strings.HasPrefix(path, "/v3/schedules/"):
+		s.handleSchedule(w, r, strings.TrimPrefix(path, "/v3/schedules/"))
+	case path == "/v3/received/detail" && r.Method == http.MethodGet:
+		s.handleReceivedDetail(w, r)
+	case path == "/v3/received" && r.Method == http.MethodGet:
+		s.handleReceived(w, r)
+	case path == "/v3/messages/detail" && r.Method == http.MethodGet:
+		s.handleMessageDetail(w, r)
+	case path == "/v3/messages" && r.Method == http.MethodGet:
+		s.handleMessages(w, r)
+	case path == "/v3/users" && r.Method == http.MethodGet:
+		s.handleUsers(w, r)
+	case path == "/v3/status/message" && r.Method == http.MethodPost:
+		s.handleMessageStatus(w, body)
+	default:
+		writeError(w, http.StatusNotFound, goserversdk.ErrorCodeInvalidParams, fmt.Sprintf("unsupported endpoint %s %s", r.Method, r.URL.Path))
+	}
+}
+
+// checkAuth 校验Basic认证
+func (s *Server) checkAuth(r *http.Request) bool {
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(s.appKey+":"+s.masterSecret))
+	return r.Header.Get("Authorization") == expected
+}
+
+// allow 执行固定窗口频率限制并写入X-Rate-Limit-*响应头
+func (s *Server) allow(w http.ResponseWriter) bool {
+	if s.rateLimit <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.windowStart) >= s.rateWindow {
+		s.windowStart = now
+		s.windowCount = 0
+	}
+	s.windowCount++
+
+	remaining := s.rateLimit - s.windowCount
+	if remaining < 0 {
+		remaining = 0
+	}
+	reset := int((s.rateWindow - now.Sub(s.windowStart) + time.Second - 1) / time.Second)
+
+	w.Header().Set("X-Rate-Limit-Limit", strconv.Itoa(s.rateLimit))
+	w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-Rate-Limit-Reset", strconv.Itoa(reset))
+	return s.windowCount <= s.rateLimit
+}
+
+// readBody 读取请求体
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	defer r.Body.Close()
+	return io.ReadAll(r.Body)
+}
+
+// writeJSON 写出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 按JPush错误格式写出错误响应
+func writeError(w http.ResponseWriter, status int, code goserversdk.ErrorCode, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    int(code),
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,349 @@
+package jpushtest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	goserversdk "github.com/mimicode/jpush-go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, srv *Server) *goserversdk.Client {
+	t.Helper()
+	client, err := goserversdk.NewClient(srv.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// recordingTB 记录断言失败信息而不让测试失败
+type recordingTB struct {
+	testing.TB
+	message string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.message = fmt.Sprintf(format, args...)
+}
+
+func notificationPush(audience *goserversdk.Audience, alert string) *goserversdk.PushRequest {
+	return &goserversdk.PushRequest{
+		Platform:     goserversdk.NewAllPlatform().GetPlatforms(),
+		Audience:     audience,
+		Notification: &goserversdk.Notification{Alert: alert},
+	}
+}
+
+func TestServer_PushAndAssertReceived(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDevice(
+		Device{RegistrationID: "reg-a", Alias: "alice", Platform: "android", Tags: []string{"vip", "beijing"}},
+		Device{RegistrationID: "reg-b", Alias: "bob", Platform: "ios", Tags: []string{"beijing"}},
+		Device{RegistrationID: "reg-c", Platform: "android", Tags: []string{"vip"}},
+	)
+	client := newTestClient(t, srv)
+
+	resp, err := client.Push.Push(notificationPush(goserversdk.NewAliasAudience("alice"), "Hello Alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, "18100000000000001", resp.MsgID)
+
+	_, err = client.Push.Push(notificationPush(&goserversdk.Audience{TagAnd: []string{"vip", "beijing"}}, "VIP Beijing"))
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(notificationPush(&goserversdk.Audience{Tag: []string{"vip"}, TagNot: []string{"beijing"}}, "VIP elsewhere"))
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(notificationPush(goserversdk.NewBroadcastAudience(), "Everyone"))
+	assert.NoError(t, err)
+
+	srv.AssertReceived(t, "alice", "Hello Alice")
+	srv.AssertReceived(t, "reg-a", "VIP Beijing")
+	srv.AssertReceived(t, "reg-c", "VIP elsewhere")
+	srv.AssertNotReceived(t, "bob", "Hello Alice")
+	srv.AssertNotReceived(t, "reg-a", "VIP elsewhere")
+	srv.AssertReceived(t, "bob", "Everyone")
+
+	assert.Len(t, srv.Pushes(), 4)
+	assert.Len(t, srv.ReceivedBy("alice"), 3)
+	assert.Equal(t, []string{"reg-a", "reg-b", "reg-c"}, srv.LastPush().Targets)
+	assert.True(t, srv.LastPush().Broadcast)
+
+	// 失败的断言输出收到的内容
+	mock := &recordingTB{TB: t}
+	srv.AssertReceived(mock, "bob", "Hello Alice")
+	assert.Contains(t, mock.message, `"bob" did not receive "Hello Alice"`)
+	assert.Contains(t, mock.message, `"Everyone"`)
+}
+
+func TestServer_PushValidation(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv)
+
+	// 没有匹配的设备
+	_, err := client.Push.Push(notificationPush(goserversdk.NewTagAudience("nobody"), "hi"))
+	assert.Equal(t, goserversdk.ErrorCodeNoValidTarget, goserversdk.GetErrorCode(err))
+
+	// 未注册的注册ID按自身处理
+	_, err = client.Push.Push(notificationPush(goserversdk.NewRegistrationIDAudience("reg-x"), "hi"))
+	assert.NoError(t, err)
+	assert.True(t, srv.HasReceived("reg-x", "hi"))
+
+	// 消息体超过4000字节
+	big := make([]byte, 4100)
+	for i := range big {
+		big[i] = 'a'
+	}
+	_, err = client.Push.Push(notificationPush(goserversdk.NewRegistrationIDAudience("reg-x"), string(big)))
+	assert.Equal(t, goserversdk.ErrorCodeMessageTooLarge, goserversdk.GetErrorCode(err))
+
+	// 与真实接口一致，接受字符串形式的广播
+	srv.AddDevice(Device{RegistrationID: "reg-y"})
+	rec := httpPost(t, srv, "/v3/push", `{"platform":"all","audience":"all","message":{"msg_content":"raw"}}`)
+	assert.Equal(t, http.StatusOK, rec)
+	srv.AssertReceived(t, "reg-y", "raw")
+
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/push", `{"platform":"all","audience":"all"}`))
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/push", `{"audience":"all","message":{"msg_content":"x"}}`))
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/push", `{"platform":["windows"],"audience":"all","message":{"msg_content":"x"}}`))
+	// 广播只接受字符串"all"
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/push", `{"platform":"all","audience":{"all":"all"},"message":{"msg_content":"x"}}`))
+
+	// 回调按接口文档校验：url可选，type为文档列出的取值
+	assert.Equal(t, http.StatusOK, httpPost(t, srv, "/v3/push", `{"platform":"all","audience":"all","message":{"msg_content":"x"},"callback":{"type":11}}`))
+	assert.Equal(t, http.StatusOK, httpPost(t, srv, "/v3/push", `{"platform":"all","audience":"all","message":{"msg_content":"x"},"callback":{"url":"http://example.com/cb","type":3}}`))
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/push", `{"platform":"all","audience":"all","message":{"msg_content":"x"},"callback":{"type":4}}`))
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/push", `{"platform":"all","audience":"all","message":{"msg_content":"x"},"callback":{"url":"/cb"}}`))
+
+	// 校验接口不记录推送
+	count := len(srv.Pushes())
+	resp, err := client.Advanced.ValidatePush(notificationPush(goserversdk.NewRegistrationIDAudience("reg-x"), "validate"))
+	assert.NoError(t, err)
+	assert.Equal(t, "0", resp.MsgID)
+	assert.Len(t, srv.Pushes(), count)
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := NewServer(WithCredentials("key", "secret"))
+	defer srv.Close()
+
+	config := srv.Config()
+	config.MasterSecret = "wrong"
+	client, err := goserversdk.NewClient(config)
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(notificationPush(goserversdk.NewRegistrationIDAudience("reg"), "hi"))
+	var jerr *goserversdk.JPushError
+	assert.True(t, errors.As(err, &jerr))
+	assert.Equal(t, goserversdk.ErrorCodeInvalidAuth, jerr.Code)
+	assert.Equal(t, http.StatusUnauthorized, jerr.HTTPStatus)
+	assert.Empty(t, srv.Pushes())
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	srv := NewServer(WithRateLimit(2, time.Minute), WithClock(func() time.Time { return now }))
+	defer srv.Close()
+	client := newTestClient(t, srv)
+	req := notificationPush(goserversdk.NewRegistrationIDAudience("reg"), "hi")
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Push.Push(req)
+		assert.NoError(t, err)
+	}
+	_, err := client.Push.Push(req)
+	var jerr *goserversdk.JPushError
+	assert.True(t, errors.As(err, &jerr))
+	assert.Equal(t, goserversdk.ErrorCodeRateLimitExceeded, jerr.Code)
+	if assert.NotNil(t, jerr.RateLimit) {
+		assert.Equal(t, 2, jerr.RateLimit.Limit)
+		assert.Equal(t, 0, jerr.RateLimit.Remaining)
+		assert.Equal(t, 60, jerr.RateLimit.Reset)
+	}
+
+	now = now.Add(time.Minute)
+	_, err = client.Push.Push(req)
+	assert.NoError(t, err)
+	assert.Len(t, srv.Pushes(), 3)
+}
+
+func TestServer_FaultInjection(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv)
+	req := notificationPush(goserversdk.NewRegistrationIDAudience("reg"), "hi")
+
+	srv.InjectFault(ServerError("/v3/push", http.StatusServiceUnavailable, 1))
+	_, err := client.Push.Push(req)
+	var jerr *goserversdk.JPushError
+	assert.True(t, errors.As(err, &jerr))
+	assert.Equal(t, http.StatusServiceUnavailable, jerr.HTTPStatus)
+	assert.True(t, jerr.IsRetryable())
+
+	// 故障次数用完后恢复正常
+	_, err = client.Push.Push(req)
+	assert.NoError(t, err)
+
+	srv.InjectFault(RateLimited("/v3/push", 3*time.Second, 1))
+	_, err = client.Push.Push(req)
+	assert.True(t, errors.As(err, &jerr))
+	assert.Equal(t, goserversdk.ErrorCodeRateLimitExceeded, jerr.Code)
+	assert.Equal(t, 3, jerr.RateLimit.Reset)
+
+	srv.InjectFault(Slow("/v3/push", 50*time.Millisecond, 1))
+	start := time.Now()
+	_, err = client.Push.Push(req)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// 只匹配指定方法
+	srv.InjectFault(Fault{Method: http.MethodGet, Status: http.StatusInternalServerError})
+	_, err = client.Push.Push(req)
+	assert.NoError(t, err)
+	srv.ClearFaults()
+
+	assert.Len(t, srv.Pushes(), 3)
+}
+
+func TestServer_CancelAndReports(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDevice(
+		Device{RegistrationID: "reg-a", Platform: "android", Tags: []string{"news"}},
+		Device{RegistrationID: "reg-i", Platform: "ios", Tags: []string{"news"}},
+		Device{RegistrationID: "reg-z", Platform: "android"},
+	)
+	client := newTestClient(t, srv)
+
+	first, err := client.Push.Push(notificationPush(goserversdk.NewTagAudience("news"), "headline"))
+	assert.NoError(t, err)
+	second, err := client.Push.Push(notificationPush(goserversdk.NewTagAudience("news"), "cancelled"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Advanced.CancelPush(second.MsgID))
+	assert.True(t, srv.PushByID(second.MsgID).Cancelled)
+	srv.AssertNotReceived(t, "reg-a", "cancelled")
+
+	details, err := client.Report.GetReceivedDetail([]string{first.MsgID, second.MsgID})
+	assert.NoError(t, err)
+	assert.Len(t, details, 2)
+	assert.Equal(t, 1, *details[0].JPushReceived)
+	assert.Equal(t, 1, *details[0].IOSAPNSReceived)
+	assert.Equal(t, 0, *details[1].JPushReceived)
+
+	messages, err := client.Report.GetMessageDetail([]string{first.MsgID})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, messages[0].Details.Notification.Target)
+
+	status, err := client.Report.GetMessageStatus(&goserversdk.MessageStatusRequest{
+		MsgID:           first.MsgID,
+		RegistrationIDs: []string{"reg-a", "reg-z", "unknown"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, goserversdk.DeliveryStatusDelivered, status["reg-a"].Status)
+	assert.Equal(t, goserversdk.DeliveryStatusNotTarget, status["reg-z"].Status)
+	assert.Equal(t, goserversdk.DeliveryStatusInvalidRegistrationID, status["unknown"].Status)
+
+	stats, err := client.Report.GetMessages([]string{first.MsgID, second.MsgID})
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, first.MsgID, stats[0].MsgID)
+		assert.Equal(t, 1, *stats[0].Android.Received)
+		assert.Equal(t, 1, *stats[0].IOS.APNSTarget)
+		assert.Equal(t, 0, *stats[1].Android.Received)
+	}
+
+	users, err := client.Report.GetUserStats(goserversdk.TimeUnitDay, "2024-01-31", 2)
+	assert.NoError(t, err)
+	if assert.Len(t, users.Items, 2) {
+		assert.Equal(t, "2024-02-01", users.Items[1].Time)
+		assert.Equal(t, 2, *users.Items[0].Android.Active)
+		assert.Equal(t, 1, *users.Items[0].IOS.Online)
+	}
+
+	cids, err := client.Advanced.GetCID(3, "")
+	assert.NoError(t, err)
+	assert.Len(t, cids.CIDList, 3)
+}
+
+func TestServer_PushSnapshots(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDevice(Device{RegistrationID: "reg-a", Alias: "alice"})
+	client := newTestClient(t, srv)
+
+	resp, err := client.Push.Push(notificationPush(goserversdk.NewAliasAudience("alice"), "hello"))
+	assert.NoError(t, err)
+
+	// 返回的推送是快照，读取时与撤销并发不产生数据竞争
+	before := srv.LastPush()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, client.Advanced.CancelPush(resp.MsgID))
+	}()
+	for _, p := range srv.ReceivedBy("alice") {
+		_ = p.Cancelled
+	}
+	_ = before.Cancelled
+	<-done
+
+	assert.False(t, before.Cancelled)
+	assert.True(t, srv.PushByID(resp.MsgID).Cancelled)
+}
+
+func TestServer_DevicesAndSchedules(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddDevice(Device{RegistrationID: "reg-a", Tags: []string{"old"}})
+
+	assert.Equal(t, http.StatusOK, httpPost(t, srv, "/v3/devices/reg-a", `{"tags":{"add":["new"],"remove":["old"]},"alias":"alice"}`))
+	d, ok := srv.Device("reg-a")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"new"}, d.Tags)
+	assert.Equal(t, "alice", d.Alias)
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/devices/missing", `{}`))
+
+	schedule := `{"name":"daily","enabled":true,"trigger":{"single":{"time":"2030-01-01 10:00:00"}},` +
+		`"push":{"platform":"all","audience":"all","notification":{"alert":"hi"}}}`
+	assert.Equal(t, http.StatusOK, httpPost(t, srv, "/v3/schedules", schedule))
+	assert.Equal(t, []string{"schedule-1"}, srv.Schedules())
+	assert.Equal(t, http.StatusBadRequest, httpPost(t, srv, "/v3/schedules", `{"name":"bad","trigger":{},"push":{"platform":"all"}}`))
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL()+"/v3/schedules/schedule-1", nil)
+	req.SetBasicAuth(DefaultAppKey, DefaultMasterSecret)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, srv.Schedules())
+
+	srv.Reset()
+	assert.Empty(t, srv.Requests())
+	_, ok = srv.Device("reg-a")
+	assert.True(t, ok)
+}
+
+func httpPost(t *testing.T, srv *Server, path, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.URL()+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth(DefaultAppKey, DefaultMasterSecret)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
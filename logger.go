@@ -0,0 +1,230 @@
+package goserversdk
+
+import (
+	"context"
+	"log/slog"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logger SDK使用的日志接口
+// keysAndValues为交替出现的键值对，例如 "msg_id", "123", "error", err
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// LogLevel 日志级别
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota // 输出全部日志（默认），实际输出仍受底层日志记录器的级别控制
+	LogLevelInfo                  // 输出Info及以上级别
+	LogLevelWarn                  // 输出Warn及以上级别
+	LogLevelError                 // 仅输出Error级别
+	LogLevelOff                   // 不输出日志
+)
+
+// 日志作用域，用于Config.LogLevels按服务设置日志级别
+const (
+	LogScopeHTTP     = "http"     // HTTP请求与响应
+	LogScopePush     = "push"     // 推送服务
+	LogScopeAdvanced = "advanced" // 高级功能服务
+	LogScopeReport   = "report"   // 统计服务
+)
+
+// levelEnabler 能够判断某级别日志是否会被输出的Logger
+type levelEnabler interface {
+	enabled(level LogLevel) bool
+}
+
+// logEnabled 判断level级别的日志是否会被输出，用于跳过脱敏等开销较大的日志参数计算
+// 无法判断的自定义Logger视为输出
+func logEnabled(l Logger, level LogLevel) bool {
+	if e, ok := l.(levelEnabler); ok {
+		return e.enabled(level)
+	}
+	return true
+}
+
+// NewZapLogger 将*zap.Logger适配为Logger
+func NewZapLogger(l *zap.Logger) Logger {
+	if l == nil {
+		return NopLogger()
+	}
+	return &zapLogger{l: l.WithOptions(zap.AddCallerSkip(1)).Sugar()}
+}
+
+// NewSlogLogger 将*slog.Logger适配为Logger
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		return NopLogger()
+	}
+	return &slogLogger{l: l}
+}
+
+// NopLogger 返回不输出任何日志的Logger
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+// WithLogLevel 返回只输出level及以上级别日志的Logger
+func WithLogLevel(l Logger, level LogLevel) Logger {
+	if l == nil || level >= LogLevelOff {
+		return NopLogger()
+	}
+	if level <= LogLevelDebug {
+		return l
+	}
+	return &leveledLogger{l: withCallerSkip(l), level: level}
+}
+
+// withCallerSkip 为leveledLogger增加的一层调用调整zap的调用方跳过层数，使日志仍指向SDK内的调用位置
+func withCallerSkip(l Logger) Logger {
+	switch v := l.(type) {
+	case *zapLogger:
+		return &zapLogger{l: v.l.Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar()}
+	case *leveledLogger:
+		return &leveledLogger{l: withCallerSkip(v.l), level: v.level}
+	default:
+		return l
+	}
+}
+
+// zapLogger zap适配器
+type zapLogger struct {
+	l *zap.SugaredLogger
+}
+
+func (z *zapLogger) Debug(msg string, keysAndValues ...interface{}) {
+	z.l.Debugw(msg, keysAndValues...)
+}
+
+func (z *zapLogger) Info(msg string, keysAndValues ...interface{}) {
+	z.l.Infow(msg, keysAndValues...)
+}
+
+func (z *zapLogger) Warn(msg string, keysAndValues ...interface{}) {
+	z.l.Warnw(msg, keysAndValues...)
+}
+
+func (z *zapLogger) Error(msg string, keysAndValues ...interface{}) {
+	z.l.Errorw(msg, keysAndValues...)
+}
+
+func (z *zapLogger) enabled(level LogLevel) bool {
+	var zl zapcore.Level
+	switch level {
+	case LogLevelDebug:
+		zl = zapcore.DebugLevel
+	case LogLevelInfo:
+		zl = zapcore.InfoLevel
+	case LogLevelWarn:
+		zl = zapcore.WarnLevel
+	default:
+		zl = zapcore.ErrorLevel
+	}
+	return zl >= z.l.Level()
+}
+
+// slogLogger log/slog适配器
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s *slogLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelDebug, msg, keysAndValues...)
+}
+
+func (s *slogLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelInfo, msg, keysAndValues...)
+}
+
+func (s *slogLogger) Warn(msg string, keysAndValues ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelWarn, msg, keysAndValues...)
+}
+
+func (s *slogLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelError, msg, keysAndValues...)
+}
+
+func (s *slogLogger) enabled(level LogLevel) bool {
+	var sl slog.Level
+	switch level {
+	case LogLevelDebug:
+		sl = slog.LevelDebug
+	case LogLevelInfo:
+		sl = slog.LevelInfo
+	case LogLevelWarn:
+		sl = slog.LevelWarn
+	default:
+		sl = slog.LevelError
+	}
+	return s.l.Enabled(context.Background(), sl)
+}
+
+// nopLogger 空日志记录器
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+func (nopLogger) enabled(LogLevel) bool        { return false }
+
+// leveledLogger 按级别过滤日志
+type leveledLogger struct {
+	l     Logger
+	level LogLevel
+}
+
+func (f *leveledLogger) Debug(msg string, keysAndValues ...interface{}) {
+	if f.level <= LogLevelDebug {
+		f.l.Debug(msg, keysAndValues...)
+	}
+}
+
+func (f *leveledLogger) Info(msg string, keysAndValues ...interface{}) {
+	if f.level <= LogLevelInfo {
+		f.l.Info(msg, keysAndValues...)
+	}
+}
+
+func (f *leveledLogger) Warn(msg string, keysAndValues ...interface{}) {
+	if f.level <= LogLevelWarn {
+		f.l.Warn(msg, keysAndValues...)
+	}
+}
+
+func (f *leveledLogger) Error(msg string, keysAndValues ...interface{}) {
+	if f.level <= LogLevelError {
+		f.l.Error(msg, keysAndValues...)
+	}
+}
+
+func (f *leveledLogger) enabled(level LogLevel) bool {
+	return f.level <= level && logEnabled(f.l, level)
+}
+
+// resolveLogger 根据配置确定基础日志记录器
+func resolveLogger(config *Config) Logger {
+	if config.LogAdapter != nil {
+		return config.LogAdapter
+	}
+	if config.Logger != nil {
+		return NewZapLogger(config.Logger)
+	}
+	return NopLogger()
+}
+
+// scopedLogger 返回指定作用域的日志记录器，LogLevels中的设置优先于LogLevel
+func scopedLogger(base Logger, config *Config, scope string) Logger {
+	level := config.LogLevel
+	if l, ok := config.LogLevels[scope]; ok {
+		level = l
+	}
+	return WithLogLevel(base, level)
+}
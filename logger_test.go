@@ -0,0 +1,148 @@
+package goserversdk
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordingLogger 记录日志调用的测试Logger
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (r *recordingLogger) record(level, msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, level+":"+msg)
+}
+
+func (r *recordingLogger) Debug(msg string, _ ...interface{}) { r.record("debug", msg) }
+func (r *recordingLogger) Info(msg string, _ ...interface{})  { r.record("info", msg) }
+func (r *recordingLogger) Warn(msg string, _ ...interface{})  { r.record("warn", msg) }
+func (r *recordingLogger) Error(msg string, _ ...interface{}) { r.record("error", msg) }
+
+func TestWithLogLevel(t *testing.T) {
+	rec := &recordingLogger{}
+	l := WithLogLevel(rec, LogLevelWarn)
+	l.Debug("d")
+	l.Info("i")
+	l.Warn("w")
+	l.Error("e")
+	assert.Equal(t, []string{"warn:w", "error:e"}, rec.entries)
+
+	assert.Same(t, rec, WithLogLevel(rec, LogLevelDebug))
+	assert.Equal(t, NopLogger(), WithLogLevel(rec, LogLevelOff))
+}
+
+func TestNewZapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewZapLogger(zap.New(core))
+	l.Info("hello", "msg_id", "123", "count", 2)
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "hello", entries[0].Message)
+	assert.Equal(t, "123", entries[0].ContextMap()["msg_id"])
+	assert.Equal(t, int64(2), entries[0].ContextMap()["count"])
+
+	assert.Equal(t, NopLogger(), NewZapLogger(nil))
+}
+
+func TestNewZapLogger_Caller(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := NewZapLogger(zap.New(core, zap.AddCaller()))
+
+	// 按级别过滤后调用方仍为本文件
+	base.Info("direct")
+	WithLogLevel(base, LogLevelInfo).Info("leveled")
+	WithLogLevel(WithLogLevel(base, LogLevelInfo), LogLevelWarn).Warn("nested")
+
+	entries := logs.All()
+	if assert.Len(t, entries, 3) {
+		for _, e := range entries {
+			assert.True(t, strings.HasSuffix(e.Caller.File, "logger_test.go"), "%s: %s", e.Message, e.Caller.File)
+		}
+	}
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	l := NewSlogLogger(slog.New(handler))
+	l.Warn("rate limited", "remaining", 0)
+
+	assert.Contains(t, buf.String(), "level=WARN")
+	assert.Contains(t, buf.String(), `msg="rate limited"`)
+	assert.Contains(t, buf.String(), "remaining=0")
+}
+
+func TestLogEnabled(t *testing.T) {
+	assert.False(t, logEnabled(NopLogger(), LogLevelError))
+
+	// 自定义Logger无法判断级别，视为输出
+	rec := &recordingLogger{}
+	assert.True(t, logEnabled(rec, LogLevelDebug))
+	assert.False(t, logEnabled(WithLogLevel(rec, LogLevelWarn), LogLevelInfo))
+	assert.True(t, logEnabled(WithLogLevel(rec, LogLevelWarn), LogLevelError))
+
+	core, _ := observer.New(zapcore.InfoLevel)
+	zl := NewZapLogger(zap.New(core))
+	assert.False(t, logEnabled(zl, LogLevelDebug))
+	assert.True(t, logEnabled(zl, LogLevelInfo))
+	assert.False(t, logEnabled(WithLogLevel(zl, LogLevelError), LogLevelWarn))
+
+	sl := NewSlogLogger(slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	assert.False(t, logEnabled(sl, LogLevelInfo))
+	assert.True(t, logEnabled(sl, LogLevelWarn))
+}
+
+func TestNewClient_LogAdapterAndScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sendno":"1","msg_id":"2"}`))
+	}))
+	defer server.Close()
+
+	rec := &recordingLogger{}
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		LogAdapter:   rec,
+		LogLevel:     LogLevelInfo,
+		LogLevels:    map[string]LogLevel{LogScopeHTTP: LogLevelOff},
+		BaseURLs:     &BaseURLs{Push: server.URL},
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(&PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewBroadcastAudience(),
+		Notification: &Notification{Alert: "hello"},
+	})
+	assert.NoError(t, err)
+
+	// HTTP作用域被关闭，推送服务只输出Info及以上级别
+	assert.NotEmpty(t, rec.entries)
+	for _, entry := range rec.entries {
+		assert.True(t, strings.HasPrefix(entry, "info:"), entry)
+	}
+}
+
+func TestNewClient_NoLogger(t *testing.T) {
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, NopLogger(), client.logger)
+}
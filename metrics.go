@@ -0,0 +1,352 @@
+package goserversdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestLabels 请求指标的标签
+type RequestLabels struct {
+	Endpoint string    // 归一化后的请求路径，不含查询参数，ID段替换为:id，如/v3/push/:id
+	Method   string    // HTTP方法
+	Status   int       // HTTP状态码，请求未得到响应时为0
+	Code     ErrorCode // 错误码，成功时为0
+}
+
+// Metrics 指标接口
+// 实现需要支持并发调用
+type Metrics interface {
+	// IncRequest 请求计数，每次请求完成后调用一次
+	IncRequest(labels RequestLabels)
+	// ObserveLatency 记录请求耗时
+	ObserveLatency(labels RequestLabels, latency time.Duration)
+	// SetRateLimit 记录响应中的频率限制信息，仅在响应携带X-Rate-Limit-*头时调用
+	SetRateLimit(endpoint string, info RateLimitInfo)
+}
+
+// MetricsMiddleware 创建记录请求指标的中间件
+// 通过Config.Metrics设置时会自动注册
+func MetricsMiddleware(m Metrics) Middleware {
+	return AfterReceive(func(ctx context.Context, req *Request, resp *APIResponse, err error, latency time.Duration) {
+		labels := RequestLabels{
+			Endpoint: normalizeEndpoint(req.Path),
+			Method:   req.Method,
+		}
+		if err != nil {
+			labels.Code = GetErrorCode(err)
+		}
+		if resp != nil {
+			labels.Status = resp.StatusCode
+			if len(resp.Headers["X-Rate-Limit-Remaining"]) > 0 {
+				m.SetRateLimit(labels.Endpoint, parseRateLimit(resp.Headers))
+			}
+		}
+		m.IncRequest(labels)
+		m.ObserveLatency(labels, latency)
+	})
+}
+
+// normalizeEndpoint 去除查询参数，并将msg_id、schedule_id等ID段替换为:id，避免指标标签基数过大
+func normalizeEndpoint(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if isIDSegment(seg) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isIDSegment 判断路径段是否为ID：纯数字，或包含数字且长度不小于16的字母数字串（如UUID、registration_id）
+func isIDSegment(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	digits := 0
+	for _, r := range seg {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return digits == len(seg) || (digits > 0 && len(seg) >= 16)
+}
+
+// MemoryMetrics 内存指标实现，保存原始观测值，主要用于测试
+type MemoryMetrics struct {
+	mu         sync.Mutex
+	requests   map[RequestLabels]int
+	latencies  map[string][]time.Duration
+	rateLimits map[string]RateLimitInfo
+}
+
+// NewMemoryMetrics 创建内存指标
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		requests:   make(map[RequestLabels]int),
+		latencies:  make(map[string][]time.Duration),
+		rateLimits: make(map[string]RateLimitInfo),
+	}
+}
+
+// IncRequest 请求计数
+func (m *MemoryMetrics) IncRequest(labels RequestLabels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[labels]++
+}
+
+// ObserveLatency 记录请求耗时
+func (m *MemoryMetrics) ObserveLatency(labels RequestLabels, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies[labels.Endpoint] = append(m.latencies[labels.Endpoint], latency)
+}
+
+// SetRateLimit 记录频率限制信息
+func (m *MemoryMetrics) SetRateLimit(endpoint string, info RateLimitInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimits[endpoint] = info
+}
+
+// Requests 返回指定标签的请求次数
+func (m *MemoryMetrics) Requests(labels RequestLabels) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests[labels]
+}
+
+// TotalRequests 返回所有请求次数之和
+func (m *MemoryMetrics) TotalRequests() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total := 0
+	for _, n := range m.requests {
+		total += n
+	}
+	return total
+}
+
+// Latencies 返回指定接口的全部耗时记录
+func (m *MemoryMetrics) Latencies(endpoint string) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.latencies[endpoint]...)
+}
+
+// RateLimit 返回指定接口最近一次记录的频率限制信息
+func (m *MemoryMetrics) RateLimit(endpoint string) (RateLimitInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, ok := m.rateLimits[endpoint]
+	return info, ok
+}
+
+// DefaultLatencyBuckets 默认耗时直方图分桶（秒）
+var DefaultLatencyBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// latencyKey 耗时直方图的标签
+type latencyKey struct {
+	endpoint string
+	method   string
+}
+
+// histogram 累积分桶直方图
+type histogram struct {
+	counts []uint64 // 与buckets一一对应，counts[i]为<=buckets[i]的观测次数
+	count  uint64
+	sum    float64
+}
+
+// PrometheusMetrics 以Prometheus文本格式导出指标，不依赖Prometheus客户端库
+// 可直接作为http.Handler挂载到/metrics
+type PrometheusMetrics struct {
+	namespace string
+	buckets   []float64
+
+	mu         sync.Mutex
+	requests   map[RequestLabels]uint64
+	latencies  map[latencyKey]*histogram
+	rateLimits map[string]RateLimitInfo
+}
+
+// NewPrometheusMetrics 创建Prometheus指标，namespace为指标名前缀（默认jpush），buckets为空时使用DefaultLatencyBuckets
+func NewPrometheusMetrics(namespace string, buckets []float64) *PrometheusMetrics {
+	if namespace == "" {
+		namespace = "jpush"
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &PrometheusMetrics{
+		namespace:  namespace,
+		buckets:    sorted,
+		requests:   make(map[RequestLabels]uint64),
+		latencies:  make(map[latencyKey]*histogram),
+		rateLimits: make(map[string]RateLimitInfo),
+	}
+}
+
+// IncRequest 请求计数
+func (p *PrometheusMetrics) IncRequest(labels RequestLabels) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[labels]++
+}
+
+// ObserveLatency 记录请求耗时
+func (p *PrometheusMetrics) ObserveLatency(labels RequestLabels, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := latencyKey{endpoint: labels.Endpoint, method: labels.Method}
+	h, ok := p.latencies[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.latencies[key] = h
+	}
+	seconds := latency.Seconds()
+	for i, upper := range p.buckets {
+		if seconds <= upper {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// SetRateLimit 记录频率限制信息
+func (p *PrometheusMetrics) SetRateLimit(endpoint string, info RateLimitInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rateLimits[endpoint] = info
+}
+
+// WriteTo 以Prometheus文本格式（0.0.4）写出全部指标
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	p.writeRequests(&b)
+	p.writeLatencies(&b)
+	p.writeRateLimits(&b)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP 实现http.Handler
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+func (p *PrometheusMetrics) writeRequests(b *strings.Builder) {
+	name := p.namespace + "_requests_total"
+	fmt.Fprintf(b, "# HELP %s Total number of JPush API requests.\n", name)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+
+	keys := make([]RequestLabels, 0, len(p.requests))
+	for k := range p.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, c := keys[i], keys[j]
+		if a.Endpoint != c.Endpoint {
+			return a.Endpoint < c.Endpoint
+		}
+		if a.Method != c.Method {
+			return a.Method < c.Method
+		}
+		if a.Status != c.Status {
+			return a.Status < c.Status
+		}
+		return a.Code < c.Code
+	})
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s{endpoint=%s,method=%s,status=\"%d\",code=\"%d\"} %d\n",
+			name, quoteLabel(k.Endpoint), quoteLabel(k.Method), k.Status, int(k.Code), p.requests[k])
+	}
+}
+
+func (p *PrometheusMetrics) writeLatencies(b *strings.Builder) {
+	name := p.namespace + "_request_duration_seconds"
+	fmt.Fprintf(b, "# HELP %s JPush API request latency in seconds.\n", name)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", name)
+
+	keys := make([]latencyKey, 0, len(p.latencies))
+	for k := range p.latencies {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		return keys[i].method < keys[j].method
+	})
+	for _, k := range keys {
+		h := p.latencies[k]
+		labels := fmt.Sprintf("endpoint=%s,method=%s", quoteLabel(k.endpoint), quoteLabel(k.method))
+		for i, upper := range p.buckets {
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(upper), h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+func (p *PrometheusMetrics) writeRateLimits(b *strings.Builder) {
+	endpoints := make([]string, 0, len(p.rateLimits))
+	for e := range p.rateLimits {
+		endpoints = append(endpoints, e)
+	}
+	sort.Strings(endpoints)
+
+	gauges := []struct {
+		suffix string
+		help   string
+		value  func(RateLimitInfo) int
+	}{
+		{"_rate_limit_limit", "Request quota of the current rate limit window.", func(i RateLimitInfo) int { return i.Limit }},
+		{"_rate_limit_remaining", "Remaining requests in the current rate limit window.", func(i RateLimitInfo) int { return i.Remaining }},
+		{"_rate_limit_reset_seconds", "Seconds until the rate limit window resets.", func(i RateLimitInfo) int { return i.Reset }},
+	}
+	for _, g := range gauges {
+		name := p.namespace + g.suffix
+		fmt.Fprintf(b, "# HELP %s %s\n", name, g.help)
+		fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+		for _, e := range endpoints {
+			fmt.Fprintf(b, "%s{endpoint=%s} %d\n", name, quoteLabel(e), g.value(p.rateLimits[e]))
+		}
+	}
+}
+
+// quoteLabel 按Prometheus文本格式转义标签值
+func quoteLabel(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
+
+// formatFloat 格式化浮点数
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
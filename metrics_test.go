@@ -0,0 +1,103 @@
+package goserversdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEndpoint(t *testing.T) {
+	assert.Equal(t, "/v3/push/cid", normalizeEndpoint("/v3/push/cid?count=1&type=push"))
+	assert.Equal(t, "/v3/push/:id", normalizeEndpoint("/v3/push/18100000012345678"))
+	assert.Equal(t, "/v3/schedules/:id", normalizeEndpoint("/v3/schedules/0eac1b80-c2ac-4b69-948b-c65b34b96512"))
+	assert.Equal(t, "/v3/received/detail", normalizeEndpoint("/v3/received/detail?msg_ids=1,2"))
+}
+
+func TestMetricsMiddleware_Client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Limit", "600")
+		w.Header().Set("X-Rate-Limit-Remaining", "599")
+		w.Header().Set("X-Rate-Limit-Reset", "60")
+		if r.URL.Path == "/v3/push/cid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"code":1004,"message":"auth failed"}}`))
+			return
+		}
+		w.Write([]byte(`{"sendno":"1","msg_id":"2"}`))
+	}))
+	defer server.Close()
+
+	metrics := NewMemoryMetrics()
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: server.URL},
+		Metrics:      metrics,
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(&PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewBroadcastAudience(),
+		Notification: &Notification{Alert: "hello"},
+	})
+	assert.NoError(t, err)
+	_, err = client.Advanced.GetCID(1, "")
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, metrics.TotalRequests())
+	assert.Equal(t, 1, metrics.Requests(RequestLabels{Endpoint: "/v3/push", Method: http.MethodPost, Status: 200}))
+	assert.Equal(t, 1, metrics.Requests(RequestLabels{
+		Endpoint: "/v3/push/cid", Method: http.MethodGet, Status: 401, Code: ErrorCodeInvalidAuth,
+	}))
+	assert.Len(t, metrics.Latencies("/v3/push"), 1)
+
+	info, ok := metrics.RateLimit("/v3/push")
+	assert.True(t, ok)
+	assert.Equal(t, RateLimitInfo{Limit: 600, Remaining: 599, Reset: 60}, info)
+}
+
+func TestPrometheusMetrics_WriteTo(t *testing.T) {
+	p := NewPrometheusMetrics("", []float64{0.5, 0.1})
+	labels := RequestLabels{Endpoint: "/v3/push", Method: http.MethodPost, Status: 200}
+	p.IncRequest(labels)
+	p.IncRequest(labels)
+	p.IncRequest(RequestLabels{Endpoint: "/v3/push", Method: http.MethodPost, Status: 429, Code: ErrorCodeRateLimitExceeded})
+	p.ObserveLatency(labels, 50*time.Millisecond)
+	p.ObserveLatency(labels, 300*time.Millisecond)
+	p.ObserveLatency(labels, 2*time.Second)
+	p.SetRateLimit("/v3/push", RateLimitInfo{Limit: 600, Remaining: 10, Reset: 5})
+
+	var b strings.Builder
+	_, err := p.WriteTo(&b)
+	assert.NoError(t, err)
+	out := b.String()
+
+	assert.Contains(t, out, "# TYPE jpush_requests_total counter\n")
+	assert.Contains(t, out, `jpush_requests_total{endpoint="/v3/push",method="POST",status="200",code="0"} 2`)
+	assert.Contains(t, out, `jpush_requests_total{endpoint="/v3/push",method="POST",status="429",code="2002"} 1`)
+	assert.Contains(t, out, "# TYPE jpush_request_duration_seconds histogram\n")
+	assert.Contains(t, out, `jpush_request_duration_seconds_bucket{endpoint="/v3/push",method="POST",le="0.1"} 1`)
+	assert.Contains(t, out, `jpush_request_duration_seconds_bucket{endpoint="/v3/push",method="POST",le="0.5"} 2`)
+	assert.Contains(t, out, `jpush_request_duration_seconds_bucket{endpoint="/v3/push",method="POST",le="+Inf"} 3`)
+	assert.Contains(t, out, `jpush_request_duration_seconds_sum{endpoint="/v3/push",method="POST"} 2.35`)
+	assert.Contains(t, out, `jpush_request_duration_seconds_count{endpoint="/v3/push",method="POST"} 3`)
+	assert.Contains(t, out, `jpush_rate_limit_remaining{endpoint="/v3/push"} 10`)
+
+	// 0.1桶应排在0.5桶之前
+	assert.Less(t, strings.Index(out, `le="0.1"`), strings.Index(out, `le="0.5"`))
+}
+
+func TestPrometheusMetrics_ServeHTTP(t *testing.T) {
+	p := NewPrometheusMetrics("myapp_jpush", nil)
+	p.IncRequest(RequestLabels{Endpoint: `/v3/"x"`, Method: http.MethodGet, Status: 200})
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Header().Get("Content-Type"), "version=0.0.4")
+	assert.Contains(t, rec.Body.String(), `myapp_jpush_requests_total{endpoint="/v3/\"x\"",method="GET",status="200",code="0"} 1`)
+}
@@ -0,0 +1,81 @@
+package goserversdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Request 经过中间件链的请求
+// 中间件可以修改Header（如注入追踪头），Body为已序列化的JSON请求体，修改时需保持合法JSON
+type Request struct {
+	Method  string      // HTTP方法
+	BaseURL string      // 服务域名，如https://api.jpush.cn
+	Path    string      // 请求路径（含查询参数），如/v3/push
+	Header  http.Header // 请求头，已包含认证、Content-Type、Accept和User-Agent
+	Body    []byte      // 请求体，无请求体时为nil
+}
+
+// RequestHandler 执行请求并返回响应
+// 请求失败时返回的*APIResponse可能为nil；HTTP状态码>=400时两者均不为nil
+type RequestHandler func(ctx context.Context, req *Request) (*APIResponse, error)
+
+// Middleware 请求中间件，包装next并返回新的RequestHandler
+type Middleware func(next RequestHandler) RequestHandler
+
+// BeforeSendFunc 请求发送前调用，返回错误时中止请求
+type BeforeSendFunc func(ctx context.Context, req *Request) error
+
+// AfterReceiveFunc 请求完成后调用，latency为包含后续中间件在内的耗时
+type AfterReceiveFunc func(ctx context.Context, req *Request, resp *APIResponse, err error, latency time.Duration)
+
+// BeforeSend 创建在请求发送前执行hook的中间件
+// hook返回的*JPushError原样返回；context取消或超时分别归为ErrorCodeCanceled和ErrorCodeRequestTimeout；
+// 其他错误包装为ErrorCodeMiddleware，不视为永久错误
+func BeforeSend(hook BeforeSendFunc) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *Request) (*APIResponse, error) {
+			if err := hook(ctx, req); err != nil {
+				var jpushErr *JPushError
+				if errors.As(err, &jpushErr) {
+					return nil, err
+				}
+				code, message := ErrorCodeMiddleware, "请求被中间件中止"
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					code, message = transportErrorCode(err)
+				}
+				return nil, withRequestPath(wrapJPushError(code, message, err), req.Path)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// AfterReceive 创建在请求完成后执行hook的中间件
+func AfterReceive(hook AfterReceiveFunc) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *Request) (*APIResponse, error) {
+			start := time.Now()
+			resp, err := next(ctx, req)
+			hook(ctx, req, resp, err, time.Since(start))
+			return resp, err
+		}
+	}
+}
+
+// Use 追加中间件，先添加的中间件位于外层，最先看到请求、最后看到响应
+// 应在发起请求前完成注册，Use与请求并发调用是不安全的
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+	c.handler = chainMiddlewares(c.middlewares, c.doRequest)
+}
+
+// chainMiddlewares 将中间件按顺序包装在final外层
+func chainMiddlewares(middlewares []Middleware, final RequestHandler) RequestHandler {
+	handler := final
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
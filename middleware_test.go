@@ -0,0 +1,142 @@
+package goserversdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_MiddlewareOrderAndHeaders(t *testing.T) {
+	var gotTrace string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTrace = r.Header.Get("X-Trace-Id")
+		w.Write([]byte(`{"sendno":"1","msg_id":"2"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *Request) (*APIResponse, error) {
+			order = append(order, "trace:before")
+			req.Header.Set("X-Trace-Id", "abc")
+			resp, err := next(ctx, req)
+			order = append(order, "trace:after")
+			return resp, err
+		}
+	}
+
+	var audited *Request
+	var auditedResp *APIResponse
+	var auditedLatency time.Duration
+	audit := AfterReceive(func(ctx context.Context, req *Request, resp *APIResponse, err error, latency time.Duration) {
+		order = append(order, "audit")
+		audited, auditedResp, auditedLatency = req, resp, latency
+	})
+
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: server.URL},
+		Middlewares:  []Middleware{trace},
+	})
+	assert.NoError(t, err)
+	client.Use(audit)
+
+	_, err = client.Push.Push(&PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewBroadcastAudience(),
+		Notification: &Notification{Alert: "hello"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "abc", gotTrace)
+	assert.Equal(t, []string{"trace:before", "audit", "trace:after"}, order)
+	assert.Equal(t, http.MethodPost, audited.Method)
+	assert.Equal(t, "/v3/push", audited.Path)
+	assert.Contains(t, string(audited.Body), `"alert":"hello"`)
+	assert.Equal(t, http.StatusOK, auditedResp.StatusCode)
+	assert.Greater(t, auditedLatency, time.Duration(0))
+}
+
+func TestBeforeSend_Abort(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	quotaErr := errors.New("tenant quota exceeded")
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: server.URL},
+		Middlewares: []Middleware{BeforeSend(func(ctx context.Context, req *Request) error {
+			return quotaErr
+		})},
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Advanced.GetCID(1, "")
+	assert.Error(t, err)
+	assert.False(t, called)
+	assert.True(t, errors.Is(err, quotaErr))
+	assert.Equal(t, ErrorCodeMiddleware, GetErrorCode(err))
+
+	// 中间件的临时故障不能被视为永久错误
+	var jpushErr *JPushError
+	if assert.True(t, errors.As(err, &jpushErr)) {
+		assert.False(t, jpushErr.IsPermanent())
+		assert.Equal(t, "/v3/push/cid?count=1", jpushErr.Path)
+	}
+}
+
+func TestBeforeSend_ErrorCodes(t *testing.T) {
+	next := func(ctx context.Context, req *Request) (*APIResponse, error) {
+		return &APIResponse{}, nil
+	}
+	run := func(hookErr error) error {
+		handler := BeforeSend(func(ctx context.Context, req *Request) error { return hookErr })(next)
+		_, err := handler(context.Background(), &Request{Path: "/v3/push"})
+		return err
+	}
+
+	// JPushError原样返回
+	rateErr := NewJPushError(ErrorCodeRateLimitExceeded, "token service rate limited")
+	assert.Same(t, rateErr, run(rateErr))
+
+	assert.Equal(t, ErrorCodeCanceled, GetErrorCode(run(context.Canceled)))
+	timeoutErr := run(fmt.Errorf("fetch token: %w", context.DeadlineExceeded))
+	assert.Equal(t, ErrorCodeRequestTimeout, GetErrorCode(timeoutErr))
+	assert.True(t, timeoutErr.(*JPushError).IsRetryable())
+}
+
+func TestAfterReceive_SeesError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"code":1004,"message":"auth failed"}}`))
+	}))
+	defer server.Close()
+
+	var gotErr error
+	var gotResp *APIResponse
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: server.URL},
+	})
+	assert.NoError(t, err)
+	client.Use(AfterReceive(func(ctx context.Context, req *Request, resp *APIResponse, err error, latency time.Duration) {
+		gotResp, gotErr = resp, err
+	}))
+
+	_, err = client.Advanced.GetCID(1, "")
+	assert.Error(t, err)
+	assert.Equal(t, ErrorCodeInvalidAuth, GetErrorCode(gotErr))
+	assert.Equal(t, http.StatusUnauthorized, gotResp.StatusCode)
+}
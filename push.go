@@ -1,55 +1,69 @@
 package goserversdk
 
 import (
-	"encoding/json"
+	"context"
 	"net/http"
-
-	"go.uber.org/zap"
 )
 
 // PushService 推送服务
 type PushService struct {
 	client *Client
+	logger Logger
 }
 
-// NewPushService 创建推送服务
+// NewPushService 创建推送服务，使用客户端的HTTP日志记录器
 func NewPushService(client *Client) *PushService {
-	return &PushService{client: client}
+	return &PushService{client: client, logger: client.logger}
 }
 
 // Push 创建推送
 // 向某单个设备或者某设备列表推送一条通知、或者消息
-func (s *PushService) Push(req *PushRequest) (*PushResponse, error) {
-	s.client.logger.Info("开始创建推送", zap.Any("request", req))
+func (s *PushService) Push(req *PushRequest) (result *PushResponse, err error) {
+	if logEnabled(s.logger, LogLevelInfo) {
+		s.logger.Info("开始创建推送", "request", s.client.redactor.value(req))
+	}
+
+	ctx, span := s.client.startSpan(context.Background(), "jpush.Push")
+	span.SetAttribute(AttrEndpoint, "/v3/push")
+	defer func() { endSpan(span, err) }()
 
 	// 验证必填参数
 	if err := s.validatePushRequest(req); err != nil {
-		s.client.logger.Error("推送请求参数验证失败", zap.Error(err))
+		s.logger.Error("推送请求参数验证失败", "error", err)
 		return nil, err
 	}
+	span.SetAttribute(AttrAudienceType, audienceType(req.Audience))
+
+	if s.client.dryRun != DryRunOff {
+		span.SetAttribute(AttrDryRun, s.client.dryRun.String())
+		return s.client.dryRunPush(ctx, s.logger, "/v3/push", req, req.Notification, req.Message, true)
+	}
 
-	resp, err := s.client.makePushRequest(http.MethodPost, "/v3/push", req)
+	resp, err := s.client.makePushRequestContext(ctx, http.MethodPost, "/v3/push", req)
 	if err != nil {
-		s.client.logger.Error("推送请求失败", zap.Error(err))
+		s.logger.Error("推送请求失败", "error", err)
 		return nil, err
 	}
 
 	// 记录频率限制信息
 	limit, remaining, reset := s.client.GetRateLimitInfo(resp)
-	s.client.logger.Info("推送频率限制信息",
-		zap.Int("limit", limit),
-		zap.Int("remaining", remaining),
-		zap.Int("reset", reset))
+	s.logger.Info("推送频率限制信息",
+		"limit", limit,
+		"remaining", remaining,
+		"reset", reset)
 
 	var pushResp PushResponse
-	if err := s.parseResponse(resp.Body, &pushResp); err != nil {
-		s.client.logger.Error("解析推送响应失败", zap.Error(err))
+	if err := resp.Decode(&pushResp); err != nil {
+		err = wrapJPushError(ErrorCodeInvalidJSON, "响应解析失败", err)
+		s.logger.Error("解析推送响应失败", "error", err)
 		return nil, err
 	}
 
-	s.client.logger.Info("推送创建成功",
-		zap.String("sendno", pushResp.SendNo),
-		zap.String("msg_id", pushResp.MsgID))
+	s.logger.Info("推送创建成功",
+		"sendno", pushResp.SendNo,
+		"msg_id", pushResp.MsgID)
+	span.SetAttribute(AttrSendNo, pushResp.SendNo)
+	span.SetAttribute(AttrMsgID, pushResp.MsgID)
 
 	return &pushResp, nil
 }
@@ -92,6 +106,13 @@ func (s *PushService) validatePushRequest(req *PushRequest) error {
 		}
 	}
 
+	// 验证回调参数
+	if req.Callback != nil {
+		if err := req.Callback.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -101,6 +122,10 @@ func (s *PushService) validateAudience(audience *Audience) error {
 
 	if audience.All != nil {
 		hasTarget = true
+		// 广播以字符串"all"发送，不能与其他目标组合使用
+		if audience.hasTargets() {
+			return NewJPushError(ErrorCodeInvalidAudience, "广播推送不能与其他推送目标组合使用")
+		}
 	}
 
 	if len(audience.Tag) > 0 {
@@ -189,17 +214,3 @@ func (s *PushService) validateMessage(message *Message) error {
 
 	return nil
 }
-
-// parseResponse 解析响应
-func (s *PushService) parseResponse(body map[string]interface{}, result interface{}) error {
-	jsonData, err := json.Marshal(body)
-	if err != nil {
-		return NewJPushError(ErrorCodeInvalidJSON, "响应序列化失败")
-	}
-
-	if err := json.Unmarshal(jsonData, result); err != nil {
-		return NewJPushError(ErrorCodeInvalidJSON, "响应解析失败")
-	}
-
-	return nil
-}
\ No newline at end of file
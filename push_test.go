@@ -531,3 +531,33 @@ func validateMessage(message *Message) error {
 	
 	return nil
 }
+
+func TestPushService_Push_InvalidCallback(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	request := NewPushRequest().
+		SetPlatform(NewAllPlatform()).
+		SetAudience(NewBroadcastAudience()).
+		SetNotification(&Notification{Alert: "hello"}).
+		SetCallback(NewCallback("http://example.com/cb").OnDelivered())
+
+	_, err = client.Push.Push(request)
+	assert.Error(t, err)
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+}
+
+func TestPushService_Push_BroadcastWithTargets(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	audience := NewBroadcastAudience()
+	audience.Tag = []string{"vip"}
+	request := NewPushRequest().
+		SetPlatform(NewAllPlatform()).
+		SetAudience(audience).
+		SetNotification(&Notification{Alert: "hello"})
+
+	_, err = client.Push.Push(request)
+	assert.Equal(t, ErrorCodeInvalidAudience, GetErrorCode(err))
+}
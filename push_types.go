@@ -1,11 +1,19 @@
 package goserversdk
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
 // Platform constants
 const (
 	PlatformAll      = "all"
 	PlatformAndroid  = "android"
 	PlatformIOS      = "ios"
 	PlatformWinPhone = "winphone"
+	PlatformHMOS     = "hmos"
+	PlatformQuickApp = "quickapp"
 )
 
 // Platform 推送平台
@@ -52,6 +60,36 @@ type Audience struct {
 	LiveActivityID   *string   `json:"live_activity_id,omitempty"` // 实时活动ID
 }
 
+// audienceJSON Audience的JSON对象形式，用于避免MarshalJSON/UnmarshalJSON递归
+type audienceJSON Audience
+
+// MarshalJSON 广播推送序列化为字符串"all"，与JPush推送API一致；与其他推送目标混用时保留对象形式，由服务端拒绝
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if a.All != nil && !a.hasTargets() {
+		return json.Marshal(*a.All)
+	}
+	return json.Marshal(audienceJSON(a))
+}
+
+// UnmarshalJSON 解析字符串"all"或推送目标对象
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var all string
+	if err := json.Unmarshal(data, &all); err == nil {
+		*a = Audience{All: &all}
+		return nil
+	}
+	return json.Unmarshal(data, (*audienceJSON)(a))
+}
+
+// hasTargets 判断是否设置了广播以外的推送目标
+func (a Audience) hasTargets() bool {
+	return len(a.Tag)+len(a.TagAnd)+len(a.TagNot)+len(a.Alias)+
+		len(a.RegistrationID)+len(a.Segment)+len(a.ABTest) > 0 || a.LiveActivityID != nil
+}
+
 // NewBroadcastAudience 创建广播推送目标
 func NewBroadcastAudience() *Audience {
 	all := "all"
@@ -186,9 +224,114 @@ type Options struct {
 
 // Callback 回调
 type Callback struct {
-	URL    string                 `json:"url"`              // 回调URL
+	URL    string                 `json:"url,omitempty"`    // 回调URL，为空时使用控制台配置的回调地址
 	Params map[string]interface{} `json:"params,omitempty"` // 回调参数
-	Type   *int                   `json:"type,omitempty"`   // 回调类型
+	Type   *int                   `json:"type,omitempty"`   // 回调类型，CallbackType按位组合
+}
+
+// CallbackType 回调类型，可按位组合，取值见JPush推送API中callback.type的说明
+type CallbackType int
+
+const (
+	CallbackTypeDelivered CallbackType = 1 << 0 // 送达回执
+	CallbackTypeClicked   CallbackType = 1 << 1 // 点击回执
+	CallbackTypeSent      CallbackType = 1 << 3 // 推送成功回执
+)
+
+// callbackTypeMask 所有已知回调类型位
+const callbackTypeMask = CallbackTypeDelivered | CallbackTypeClicked | CallbackTypeSent
+
+const (
+	maxCallbackURLLength  = 512  // 回调URL最大长度
+	maxCallbackParamsSize = 1024 // 回调参数序列化为JSON后的最大字节数
+)
+
+// NewCallback 创建回调，通过On*方法选择回调类型；未选择时请求不携带type字段，由JPush按默认类型回调
+func NewCallback(url string) *Callback {
+	return &Callback{URL: url}
+}
+
+// OnDelivered 开启送达回执
+func (c *Callback) OnDelivered() *Callback {
+	return c.addType(CallbackTypeDelivered)
+}
+
+// OnClicked 开启点击回执
+func (c *Callback) OnClicked() *Callback {
+	return c.addType(CallbackTypeClicked)
+}
+
+// OnSent 开启推送成功回执
+func (c *Callback) OnSent() *Callback {
+	return c.addType(CallbackTypeSent)
+}
+
+// WithParams 合并自定义回调参数，回调时原样返回
+func (c *Callback) WithParams(params map[string]interface{}) *Callback {
+	if c.Params == nil {
+		c.Params = make(map[string]interface{}, len(params))
+	}
+	for k, v := range params {
+		c.Params[k] = v
+	}
+	return c
+}
+
+// WithParam 设置单个自定义回调参数
+func (c *Callback) WithParam(key string, value interface{}) *Callback {
+	return c.WithParams(map[string]interface{}{key: value})
+}
+
+// Types 返回已选择的回调类型
+func (c *Callback) Types() CallbackType {
+	if c.Type == nil {
+		return 0
+	}
+	return CallbackType(*c.Type)
+}
+
+// Has 判断是否选择了指定回调类型
+func (c *Callback) Has(t CallbackType) bool {
+	return c.Types()&t == t
+}
+
+// addType 将回调类型加入位掩码
+func (c *Callback) addType(t CallbackType) *Callback {
+	v := int(c.Types() | t)
+	c.Type = &v
+	return c
+}
+
+// Validate 校验回调地址、类型和参数大小
+// 回调地址和类型均为可选，未设置地址时使用控制台配置的回调地址，未设置类型时由JPush按默认类型回调
+func (c *Callback) Validate() error {
+	if c.URL != "" {
+		if len(c.URL) > maxCallbackURLLength {
+			return NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("回调地址长度不能超过%d", maxCallbackURLLength))
+		}
+		u, err := url.Parse(c.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return NewJPushError(ErrorCodeInvalidParams, "回调地址必须是有效的https地址")
+		}
+	}
+
+	if c.Type != nil {
+		t := CallbackType(*c.Type)
+		if t == 0 || t&^callbackTypeMask != 0 {
+			return NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("回调类型无效: %d", *c.Type))
+		}
+	}
+
+	if len(c.Params) > 0 {
+		data, err := json.Marshal(c.Params)
+		if err != nil {
+			return wrapJPushError(ErrorCodeInvalidParams, "回调参数无法序列化", err)
+		}
+		if len(data) > maxCallbackParamsSize {
+			return NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("回调参数不能超过%d字节", maxCallbackParamsSize))
+		}
+	}
+	return nil
 }
 
 // PushRequest 推送请求
@@ -206,7 +349,24 @@ type PushRequest struct {
 // PushResponse 推送响应
 type PushResponse struct {
 	SendNo string `json:"sendno"` // 推送序号
-	MsgID  string `json:"msg_id"` // 消息ID
+	MsgID  string `json:"msg_id"` // 消息ID，试运行时为DryRunMsgID
+	DryRun bool   `json:"-"`      // 是否为试运行的合成响应，推送未实际下发
+}
+
+// UnmarshalJSON 兼容字符串和数字形式的msg_id、sendno
+func (r *PushResponse) UnmarshalJSON(data []byte) error {
+	type alias PushResponse
+	aux := struct {
+		*alias
+		SendNo flexibleID `json:"sendno"`
+		MsgID  flexibleID `json:"msg_id"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.SendNo = string(aux.SendNo)
+	r.MsgID = string(aux.MsgID)
+	return nil
 }
 
 // NewPushRequest 创建推送请求
@@ -2,6 +2,7 @@ package goserversdk
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -154,6 +155,36 @@ func TestAudience_JSON(t *testing.T) {
 	}
 }
 
+func TestAudience_BroadcastJSON(t *testing.T) {
+	data, err := json.Marshal(NewBroadcastAudience())
+	assert.NoError(t, err)
+	assert.Equal(t, `"all"`, string(data))
+
+	data, err = json.Marshal(&PushRequest{Platform: PlatformAll, Audience: NewBroadcastAudience()})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"audience":"all"`)
+
+	// 与其他目标混用时保留对象形式
+	mixed := NewBroadcastAudience()
+	mixed.Tag = []string{"vip"}
+	data, err = json.Marshal(mixed)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"all":"all","tag":["vip"]}`, string(data))
+
+	for _, input := range []string{`"all"`, `{"all":"all"}`} {
+		var decoded Audience
+		assert.NoError(t, json.Unmarshal([]byte(input), &decoded), input)
+		if assert.NotNil(t, decoded.All, input) {
+			assert.Equal(t, "all", *decoded.All)
+		}
+	}
+
+	var decoded Audience
+	assert.NoError(t, json.Unmarshal([]byte(`{"tag":["a"]}`), &decoded))
+	assert.Nil(t, decoded.All)
+	assert.Equal(t, []string{"a"}, decoded.Tag)
+}
+
 func TestNotification_JSON(t *testing.T) {
 	notification := &Notification{
 		Alert: "Global alert",
@@ -272,7 +303,7 @@ func TestPushResponse_JSON(t *testing.T) {
 
 func TestMessageStatusRequest_JSON(t *testing.T) {
 	statusReq := &MessageStatusRequest{
-		MsgID:           int64(123456789),
+		MsgID:           "123456789",
 		RegistrationIDs: []string{"test_registration_id"},
 		Date:            "2023-10-01",
 	}
@@ -280,6 +311,7 @@ func TestMessageStatusRequest_JSON(t *testing.T) {
 	data, err := json.Marshal(statusReq)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, data)
+	assert.Contains(t, string(data), `"msg_id":123456789`)
 
 	var decoded MessageStatusRequest
 	err = json.Unmarshal(data, &decoded)
@@ -404,4 +436,61 @@ func TestNilPointers(t *testing.T) {
 	assert.Nil(t, decoded.Notification)
 	assert.Nil(t, decoded.Message)
 	assert.Nil(t, decoded.Options)
-}
\ No newline at end of file
+}
+
+func TestCallbackBuilder(t *testing.T) {
+	cb := NewCallback("https://example.com/jpush/callback").
+		OnDelivered().
+		OnClicked().
+		WithParams(map[string]interface{}{"campaign": "spring"}).
+		WithParam("batch", 3)
+
+	assert.NoError(t, cb.Validate())
+	assert.Equal(t, 3, *cb.Type)
+	assert.True(t, cb.Has(CallbackTypeDelivered|CallbackTypeClicked))
+	assert.False(t, cb.Has(CallbackTypeSent))
+	assert.Equal(t, "spring", cb.Params["campaign"])
+
+	data, err := json.Marshal(cb)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"url":"https://example.com/jpush/callback","params":{"campaign":"spring","batch":3},"type":3}`, string(data))
+
+	// 重复开启同一类型不改变位掩码
+	assert.Equal(t, 3, *cb.OnDelivered().Type)
+
+	// 未设置地址时不携带url字段，使用控制台配置的回调地址
+	data, err = json.Marshal(&Callback{Type: intPtr(1)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":1}`, string(data))
+}
+
+func TestCallback_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		callback *Callback
+		wantErr  bool
+	}{
+		{"no type", NewCallback("https://example.com/cb"), false},
+		{"no url", NewCallback("").OnDelivered(), false},
+		{"no url with params", &Callback{Params: map[string]interface{}{"campaign": "spring"}}, false},
+		{"http url", NewCallback("http://example.com/cb").OnDelivered(), true},
+		{"relative url", NewCallback("/cb").OnDelivered(), true},
+		{"unknown type bit", &Callback{URL: "https://example.com/cb", Type: intPtr(16)}, true},
+		{"undocumented type bit", &Callback{URL: "https://example.com/cb", Type: intPtr(4)}, true},
+		{"zero type", &Callback{URL: "https://example.com/cb", Type: intPtr(0)}, true},
+		{"params too large", NewCallback("https://example.com/cb").OnClicked().
+			WithParam("blob", strings.Repeat("x", maxCallbackParamsSize)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.callback.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
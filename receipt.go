@@ -0,0 +1,475 @@
+package goserversdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 厂商通道
+const (
+	VendorHuawei = "huawei"
+	VendorHonor  = "honor"
+	VendorXiaomi = "xiaomi"
+	VendorOPPO   = "oppo"
+	VendorVivo   = "vivo"
+	VendorHMOS   = "hmos"
+)
+
+// DeliveryEventStatus 厂商回执归一化后的状态
+type DeliveryEventStatus int
+
+const (
+	DeliveryEventUnknown       DeliveryEventStatus = iota // 无法识别的回执状态
+	DeliveryEventDelivered                                // 已送达
+	DeliveryEventClicked                                  // 已点击
+	DeliveryEventInvalidTarget                            // 目标无效，如token失效、应用已卸载
+	DeliveryEventFailed                                   // 其他原因导致的下发失败
+)
+
+// String 返回状态名称
+func (s DeliveryEventStatus) String() string {
+	switch s {
+	case DeliveryEventDelivered:
+		return "delivered"
+	case DeliveryEventClicked:
+		return "clicked"
+	case DeliveryEventInvalidTarget:
+		return "invalid_target"
+	case DeliveryEventFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// DeliveryEvent 归一化的送达事件，由厂商回执或JPush回调转换而来
+type DeliveryEvent struct {
+	Vendor         string              // 厂商通道，如huawei、xiaomi
+	MsgID          string              // JPush消息ID
+	RegistrationID string              // JPush注册ID
+	VendorToken    string              // 厂商设备token
+	Status         DeliveryEventStatus // 归一化状态
+	VendorStatus   string              // 厂商原始状态码或事件类型
+	Time           time.Time           // 回执时间，厂商未提供时为零值
+	Raw            json.RawMessage     // 原始回执JSON
+}
+
+// Key 返回事件的唯一键：msg_id:registration_id
+func (e *DeliveryEvent) Key() string {
+	return e.MsgID + ":" + e.RegistrationID
+}
+
+// DeliveryEvent 将JPush送达、点击回调转换为DeliveryEvent，便于与厂商回执统一处理
+// 其他类型的回调转换为DeliveryEventUnknown，VendorStatus为回调事件类型名称
+func (e *CallbackEvent) DeliveryEvent() *DeliveryEvent {
+	status := DeliveryEventUnknown
+	switch e.Type {
+	case CallbackEventDelivered:
+		status = DeliveryEventDelivered
+	case CallbackEventClicked:
+		status = DeliveryEventClicked
+	}
+	return &DeliveryEvent{
+		Vendor:         e.Channel,
+		MsgID:          e.MsgID,
+		RegistrationID: e.RegistrationID,
+		Status:         status,
+		VendorStatus:   e.Type.String(),
+		Time:           e.Time,
+		Raw:            e.Raw,
+	}
+}
+
+// ReceiptResolver 将厂商回执中的标签和token解析为JPush的msg_id与registration_id
+// tag为推送时携带的回执标签（华为/荣耀/鸿蒙的biTag，小米/OPPO/vivo的param），token为厂商设备token
+type ReceiptResolver func(ctx context.Context, vendor, tag, token string) (msgID, registrationID string, err error)
+
+// defaultReceiptResolver 将标签作为msg_id，token作为registration_id
+func defaultReceiptResolver(ctx context.Context, vendor, tag, token string) (string, string, error) {
+	return tag, token, nil
+}
+
+// DeliveryFunc 送达事件处理函数，返回错误时响应500以便厂商重试
+// 同一请求中的事件全部分发后才响应，厂商重试时整批事件会再次分发（至少一次投递），处理函数应按DeliveryEvent.Key去重
+type DeliveryFunc func(ctx context.Context, event *DeliveryEvent) error
+
+// DeliveryChannel 返回将事件发送到ch的DeliveryFunc，ch已满时阻塞直到请求被取消
+func DeliveryChannel(ch chan<- *DeliveryEvent) DeliveryFunc {
+	return func(ctx context.Context, event *DeliveryEvent) error {
+		select {
+		case ch <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ReceiptHandlerConfig 厂商回执接收配置
+type ReceiptHandlerConfig struct {
+	Vendor   string          // 厂商通道，如VendorHuawei；为空时从URL路径最后一段读取，如/receipts/huawei
+	Resolver ReceiptResolver // msg_id与registration_id解析函数，默认将标签作为msg_id、token作为registration_id
+	OnEvent  DeliveryFunc    // 事件处理函数
+	Logger   Logger          // 日志记录器，默认不输出日志
+
+	// 认证配置，可同时设置；均未设置时不校验，任一项校验失败时响应401
+	Username string // 设置后校验Authorization: Basic base64(Username:Password)
+	Password string // Basic认证密码
+	Token    string // 设置后校验X-Receipt-Token请求头或URL中的token参数
+}
+
+// receiptTokenHeader 厂商回执共享令牌请求头
+const receiptTokenHeader = "X-Receipt-Token"
+
+// ReceiptHandler 接收厂商回执的http.Handler
+type ReceiptHandler struct {
+	config ReceiptHandlerConfig
+	logger Logger
+}
+
+// NewReceiptHandler 创建厂商回执接收处理器
+func NewReceiptHandler(config ReceiptHandlerConfig) *ReceiptHandler {
+	if config.Resolver == nil {
+		config.Resolver = defaultReceiptResolver
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = NopLogger()
+	}
+	return &ReceiptHandler{config: config, logger: logger}
+}
+
+// ServeHTTP 实现http.Handler
+func (h *ReceiptHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkAuth(r) {
+		h.logger.Warn("厂商回执认证失败", "remote_addr", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vendor := h.config.Vendor
+	if vendor == "" {
+		vendor = r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackBodySize+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxCallbackBodySize {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	events, err := ParseVendorReceipt(vendor, body)
+	if err != nil {
+		h.logger.Warn("厂商回执解析失败", "vendor", vendor, "error", err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), vendor, events); err != nil {
+		h.logger.Error("厂商回执处理失败", "vendor", vendor, "error", err)
+		http.Error(w, "handler error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, `{"code":0,"message":"success"}`)
+}
+
+// dispatch 解析每个事件的msg_id并分发，单个事件失败不影响其余事件，返回合并后的错误
+func (h *ReceiptHandler) dispatch(ctx context.Context, vendor string, events []*DeliveryEvent) error {
+	var errs []error
+	for _, event := range events {
+		msgID, regID, err := h.config.Resolver(ctx, vendor, event.MsgID, event.VendorToken)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolve tag %s: %w", event.MsgID, err))
+			continue
+		}
+		event.MsgID, event.RegistrationID = msgID, regID
+
+		if h.config.OnEvent == nil {
+			continue
+		}
+		if err := h.config.OnEvent(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("event %s: %w", event.Key(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkAuth 校验Basic认证和共享令牌
+func (h *ReceiptHandler) checkAuth(r *http.Request) bool {
+	if h.config.Username != "" || h.config.Password != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(h.config.Username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(h.config.Password)) != 1 {
+			return false
+		}
+	}
+	if h.config.Token != "" {
+		token := r.Header.Get(receiptTokenHeader)
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.config.Token)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseVendorReceipt 解析厂商回执请求体
+// 返回事件的MsgID为回执标签（biTag或param）、VendorToken为厂商设备token，RegistrationID为空，需经ReceiptResolver转换
+func ParseVendorReceipt(vendor string, body []byte) ([]*DeliveryEvent, error) {
+	switch vendor {
+	case VendorHuawei, VendorHonor, VendorHMOS:
+		return parseStatusesReceipt(vendor, body)
+	case VendorXiaomi:
+		return parseXiaomiReceipt(body)
+	case VendorOPPO:
+		return parseOPPOReceipt(body)
+	case VendorVivo:
+		return parseVivoReceipt(body)
+	default:
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("unsupported receipt vendor: %q", vendor))
+	}
+}
+
+// statusesReceipt 华为、荣耀、鸿蒙回执格式
+type statusesReceipt struct {
+	Statuses []json.RawMessage `json:"statuses"`
+}
+
+type statusesReceiptItem struct {
+	BiTag     string `json:"biTag"`
+	Token     string `json:"token"`
+	Status    int    `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// parseStatusesReceipt 解析华为、荣耀、鸿蒙回执
+// status: 0送达，2应用未安装，5 token不存在，其他为下发失败
+func parseStatusesReceipt(vendor string, body []byte) ([]*DeliveryEvent, error) {
+	var receipt statusesReceipt
+	if err := decodeJSON(body, &receipt); err != nil {
+		return nil, err
+	}
+
+	events := make([]*DeliveryEvent, 0, len(receipt.Statuses))
+	for _, raw := range receipt.Statuses {
+		var item statusesReceiptItem
+		if err := decodeJSON(raw, &item); err != nil {
+			return nil, err
+		}
+		status := DeliveryEventFailed
+		switch item.Status {
+		case 0:
+			status = DeliveryEventDelivered
+		case 2, 5:
+			status = DeliveryEventInvalidTarget
+		}
+		events = append(events, &DeliveryEvent{
+			Vendor:       vendor,
+			MsgID:        item.BiTag,
+			VendorToken:  item.Token,
+			Status:       status,
+			VendorStatus: strconv.Itoa(item.Status),
+			Time:         receiptTime(item.Timestamp),
+			Raw:          raw,
+		})
+	}
+	return events, nil
+}
+
+// xiaomiReceiptItem 小米回执格式，外层为以小米消息ID为键的对象
+type xiaomiReceiptItem struct {
+	Param     string `json:"param"`
+	Type      int    `json:"type"`
+	Targets   string `json:"targets"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// parseXiaomiReceipt 解析小米回执，请求体可以是JSON或data=<JSON>表单
+// type: 1送达，2点击，16目标设备无效，其他为下发失败
+func parseXiaomiReceipt(body []byte) ([]*DeliveryEvent, error) {
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("data=")) {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, err
+		}
+		body = []byte(form.Get("data"))
+	}
+
+	var items map[string]json.RawMessage
+	if err := decodeJSON(body, &items); err != nil {
+		return nil, err
+	}
+
+	var events []*DeliveryEvent
+	for _, vendorMsgID := range sortedKeys(items) {
+		raw := items[vendorMsgID]
+		var item xiaomiReceiptItem
+		if err := decodeJSON(raw, &item); err != nil {
+			return nil, err
+		}
+		status := DeliveryEventFailed
+		switch item.Type {
+		case 1:
+			status = DeliveryEventDelivered
+		case 2:
+			status = DeliveryEventClicked
+		case 16:
+			status = DeliveryEventInvalidTarget
+		}
+		for _, token := range splitTargets(item.Targets) {
+			events = append(events, &DeliveryEvent{
+				Vendor:       VendorXiaomi,
+				MsgID:        item.Param,
+				VendorToken:  token,
+				Status:       status,
+				VendorStatus: strconv.Itoa(item.Type),
+				Time:         receiptTime(item.Timestamp),
+				Raw:          raw,
+			})
+		}
+	}
+	return events, nil
+}
+
+// oppoReceiptItem OPPO回执格式
+type oppoReceiptItem struct {
+	Param           string     `json:"param"`
+	RegistrationIDs string     `json:"registrationIds"`
+	EventType       string     `json:"eventType"`
+	EventTime       flexibleID `json:"eventTime"`
+}
+
+// parseOPPOReceipt 解析OPPO回执
+// eventType: push_arrive送达，click点击，regid_invalid目标无效，其他为下发失败
+func parseOPPOReceipt(body []byte) ([]*DeliveryEvent, error) {
+	var raws []json.RawMessage
+	if err := decodeJSON(body, &raws); err != nil {
+		return nil, err
+	}
+
+	var events []*DeliveryEvent
+	for _, raw := range raws {
+		var item oppoReceiptItem
+		if err := decodeJSON(raw, &item); err != nil {
+			return nil, err
+		}
+		status := DeliveryEventFailed
+		switch item.EventType {
+		case "push_arrive":
+			status = DeliveryEventDelivered
+		case "click":
+			status = DeliveryEventClicked
+		case "regid_invalid":
+			status = DeliveryEventInvalidTarget
+		}
+		ts, _ := strconv.ParseInt(string(item.EventTime), 10, 64)
+		for _, token := range splitTargets(item.RegistrationIDs) {
+			events = append(events, &DeliveryEvent{
+				Vendor:       VendorOPPO,
+				MsgID:        item.Param,
+				VendorToken:  token,
+				Status:       status,
+				VendorStatus: item.EventType,
+				Time:         receiptTime(ts),
+				Raw:          raw,
+			})
+		}
+	}
+	return events, nil
+}
+
+// vivoReceiptItem vivo回执格式，外层为以vivo任务ID为键的对象
+type vivoReceiptItem struct {
+	Param   string `json:"param"`
+	Targets string `json:"targets"`
+	AckType int    `json:"ackType"`
+}
+
+// parseVivoReceipt 解析vivo回执
+// vivo仅回执送达事件，ackType为0或1时视为送达
+func parseVivoReceipt(body []byte) ([]*DeliveryEvent, error) {
+	var items map[string]json.RawMessage
+	if err := decodeJSON(body, &items); err != nil {
+		return nil, err
+	}
+
+	var events []*DeliveryEvent
+	for _, taskID := range sortedKeys(items) {
+		raw := items[taskID]
+		var item vivoReceiptItem
+		if err := decodeJSON(raw, &item); err != nil {
+			return nil, err
+		}
+		status := DeliveryEventFailed
+		if item.AckType == 0 || item.AckType == 1 {
+			status = DeliveryEventDelivered
+		}
+		for _, token := range splitTargets(item.Targets) {
+			events = append(events, &DeliveryEvent{
+				Vendor:       VendorVivo,
+				MsgID:        item.Param,
+				VendorToken:  token,
+				Status:       status,
+				VendorStatus: strconv.Itoa(item.AckType),
+				Raw:          raw,
+			})
+		}
+	}
+	return events, nil
+}
+
+// receiptTime 将毫秒或秒时间戳转换为时间，0返回零值
+func receiptTime(ts int64) time.Time {
+	if ts <= 0 {
+		return time.Time{}
+	}
+	return callbackTime(ts)
+}
+
+// splitTargets 拆分逗号分隔的设备token
+func splitTargets(targets string) []string {
+	var out []string
+	for _, t := range strings.Split(targets, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// sortedKeys 返回排序后的键，保证事件顺序稳定
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package goserversdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVendorReceipt_Huawei(t *testing.T) {
+	body := `{"statuses":[
+		{"biTag":"18100000012345678","appid":"100","token":"tokenA","status":0,"timestamp":1700000000000,"requestId":"r1"},
+		{"biTag":"18100000012345678","appid":"100","token":"tokenB","status":5,"timestamp":1700000000000,"requestId":"r1"},
+		{"biTag":"18100000012345678","appid":"100","token":"tokenC","status":6,"timestamp":1700000000000,"requestId":"r1"}
+	]}`
+
+	for _, vendor := range []string{VendorHuawei, VendorHonor, VendorHMOS} {
+		events, err := ParseVendorReceipt(vendor, []byte(body))
+		assert.NoError(t, err)
+		assert.Len(t, events, 3)
+		assert.Equal(t, vendor, events[0].Vendor)
+		assert.Equal(t, "18100000012345678", events[0].MsgID)
+		assert.Equal(t, "tokenA", events[0].VendorToken)
+		assert.Equal(t, DeliveryEventDelivered, events[0].Status)
+		assert.Equal(t, time.UnixMilli(1700000000000), events[0].Time)
+		assert.Equal(t, DeliveryEventInvalidTarget, events[1].Status)
+		assert.Equal(t, DeliveryEventFailed, events[2].Status)
+		assert.Equal(t, "6", events[2].VendorStatus)
+	}
+}
+
+func TestParseVendorReceipt_Xiaomi(t *testing.T) {
+	data := `{"scm01b":{"param":"111","type":1,"targets":"regA,regB","timestamp":1700000000000},` +
+		`"scm01c":{"param":"222","type":16,"targets":"regC"}}`
+
+	for _, body := range []string{data, "data=" + url.QueryEscape(data)} {
+		events, err := ParseVendorReceipt(VendorXiaomi, []byte(body))
+		assert.NoError(t, err)
+		assert.Len(t, events, 3)
+		assert.Equal(t, "111", events[0].MsgID)
+		assert.Equal(t, "regB", events[1].VendorToken)
+		assert.Equal(t, DeliveryEventDelivered, events[1].Status)
+		assert.Equal(t, DeliveryEventInvalidTarget, events[2].Status)
+		assert.True(t, events[2].Time.IsZero())
+	}
+}
+
+func TestParseVendorReceipt_OPPOAndVivo(t *testing.T) {
+	oppo := `[{"messageId":"m1","taskId":"t1","registrationIds":"regA,regB","param":"111","eventTime":"1700000000000","eventType":"push_arrive"},
+		{"messageId":"m1","taskId":"t1","registrationIds":"regC","param":"111","eventTime":1700000000000,"eventType":"regid_invalid"}]`
+	events, err := ParseVendorReceipt(VendorOPPO, []byte(oppo))
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, DeliveryEventDelivered, events[0].Status)
+	assert.Equal(t, "push_arrive", events[0].VendorStatus)
+	assert.Equal(t, time.UnixMilli(1700000000000), events[2].Time)
+	assert.Equal(t, DeliveryEventInvalidTarget, events[2].Status)
+
+	vivo := `{"taskA":{"param":"111","targets":"regA"},"taskB":{"param":"222","targets":"regB,regC","ackType":1}}`
+	events, err = ParseVendorReceipt(VendorVivo, []byte(vivo))
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, "222", events[2].MsgID)
+	assert.Equal(t, DeliveryEventDelivered, events[2].Status)
+
+	_, err = ParseVendorReceipt("meizu", []byte(`{}`))
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+}
+
+func TestReceiptHandler_Channel(t *testing.T) {
+	ch := make(chan *DeliveryEvent, 10)
+	h := NewReceiptHandler(ReceiptHandlerConfig{
+		Resolver: func(ctx context.Context, vendor, tag, token string) (string, string, error) {
+			return tag, "jpush-" + token, nil
+		},
+		OnEvent: DeliveryChannel(ch),
+	})
+
+	body := `{"statuses":[{"biTag":"111","token":"tokenA","status":0}]}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/receipts/huawei", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Len(t, ch, 1)
+	event := <-ch
+	assert.Equal(t, VendorHuawei, event.Vendor)
+	assert.Equal(t, "111:jpush-tokenA", event.Key())
+	assert.Equal(t, "tokenA", event.VendorToken)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/receipts/unknown", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestReceiptHandler_HandlerError(t *testing.T) {
+	var dispatched []string
+	h := NewReceiptHandler(ReceiptHandlerConfig{
+		Vendor: VendorVivo,
+		OnEvent: func(ctx context.Context, event *DeliveryEvent) error {
+			dispatched = append(dispatched, event.VendorToken)
+			if event.VendorToken == "a" {
+				return errors.New("queue full")
+			}
+			return nil
+		},
+	})
+
+	// 第一个事件失败时其余事件仍然分发，最后统一响应500
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/vivo-receipts", strings.NewReader(`{"t":{"param":"1","targets":"a,b,c"}}`)))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, []string{"a", "b", "c"}, dispatched)
+}
+
+func TestReceiptHandler_Auth(t *testing.T) {
+	var count int
+	onEvent := func(ctx context.Context, event *DeliveryEvent) error {
+		count++
+		return nil
+	}
+	body := `{"statuses":[{"biTag":"111","token":"tokenA","status":0}]}`
+	serve := func(h http.Handler, target string, prepare func(r *http.Request)) int {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		if prepare != nil {
+			prepare(req)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	basic := NewReceiptHandler(ReceiptHandlerConfig{Vendor: VendorHuawei, Username: "huawei", Password: "secret", OnEvent: onEvent})
+	assert.Equal(t, http.StatusUnauthorized, serve(basic, "/receipts", nil))
+	assert.Equal(t, http.StatusUnauthorized, serve(basic, "/receipts", func(r *http.Request) { r.SetBasicAuth("huawei", "wrong") }))
+	assert.Equal(t, http.StatusOK, serve(basic, "/receipts", func(r *http.Request) { r.SetBasicAuth("huawei", "secret") }))
+
+	token := NewReceiptHandler(ReceiptHandlerConfig{Vendor: VendorHuawei, Token: "t0ken", OnEvent: onEvent})
+	assert.Equal(t, http.StatusUnauthorized, serve(token, "/receipts?token=bad", nil))
+	assert.Equal(t, http.StatusOK, serve(token, "/receipts?token=t0ken", nil))
+	assert.Equal(t, http.StatusOK, serve(token, "/receipts", func(r *http.Request) { r.Header.Set("X-Receipt-Token", "t0ken") }))
+
+	assert.Equal(t, 3, count)
+}
+
+func TestCallbackEvent_DeliveryEvent(t *testing.T) {
+	event := &CallbackEvent{MsgID: "1", RegistrationID: "reg", Type: CallbackEventDelivered, Channel: VendorXiaomi}
+	d := event.DeliveryEvent()
+	assert.Equal(t, DeliveryEventDelivered, d.Status)
+	assert.Equal(t, "delivered", d.VendorStatus)
+	assert.Equal(t, VendorXiaomi, d.Vendor)
+	assert.Equal(t, "1:reg", d.Key())
+
+	event.Type = CallbackEventClicked
+	assert.Equal(t, "clicked", event.DeliveryEvent().Status.String())
+
+	// 推送成功回执不代表送达
+	event.Type = CallbackEventSent
+	assert.Equal(t, DeliveryEventUnknown, event.DeliveryEvent().Status)
+
+	// 未在推送API文档中定义的类型
+	event.Type = CallbackEventType(4)
+	d = event.DeliveryEvent()
+	assert.Equal(t, DeliveryEventUnknown, d.Status)
+	assert.Equal(t, "unknown(4)", d.VendorStatus)
+}
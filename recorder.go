@@ -0,0 +1,337 @@
+package goserversdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RecorderMode 录制回放模式
+type RecorderMode int
+
+const (
+	RecorderReplay RecorderMode = iota // 只回放夹具文件中的响应，不发出真实请求（默认）
+	RecorderRecord                     // 发出真实请求并覆盖写入夹具文件
+	RecorderAuto                       // 夹具文件存在时回放，否则录制
+)
+
+// String 返回模式名称
+func (m RecorderMode) String() string {
+	switch m {
+	case RecorderReplay:
+		return "replay"
+	case RecorderRecord:
+		return "record"
+	case RecorderAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("RecorderMode(%d)", int(m))
+	}
+}
+
+// ErrNoRecording 回放模式下没有与请求匹配的录制记录
+var ErrNoRecording = errors.New("no recorded interaction matches request")
+
+// Interaction 一次录制的请求与响应
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest 录制的请求，认证等敏感请求头已脱敏
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"` // 请求路径（含查询参数），不含域名，便于跨环境回放
+	Header http.Header `json:"header,omitempty"`
+	Body   fixtureBody `json:"body,omitempty"`
+}
+
+// RecordedResponse 录制的响应
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       fixtureBody `json:"body,omitempty"`
+}
+
+// fixtureBody 夹具中的消息体，JSON内容原样保存以便阅读和比对，其他内容保存为字符串
+type fixtureBody []byte
+
+// MarshalJSON 实现json.Marshaler
+func (b fixtureBody) MarshalJSON() ([]byte, error) {
+	if len(b) == 0 {
+		return []byte(`""`), nil
+	}
+	if json.Valid(b) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler
+func (b *fixtureBody) UnmarshalJSON(data []byte) error {
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*b = fixtureBody(s)
+		return nil
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// fixtureFile 夹具文件格式
+type fixtureFile struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder 录制回放传输层，用于基于真实响应的确定性测试
+// 录制时将请求/响应写入JSON夹具文件（认证信息已脱敏），回放时按方法、路径和请求体匹配录制记录
+// 可以设置到Config.Recorder，也可以直接作为http.RoundTripper使用
+type Recorder struct {
+	path string
+	mode RecorderMode
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	redactor     *redactor
+}
+
+// NewRecorder 创建录制回放传输层
+// path为夹具文件路径；回放模式下文件必须存在，自动模式下文件不存在时切换为录制
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{
+		path:     path,
+		mode:     mode,
+		redactor: newRedactor(RedactionMasked, nil),
+	}
+
+	if mode == RecorderAuto {
+		if _, err := os.Stat(path); err == nil {
+			r.mode = RecorderReplay
+		} else if errors.Is(err, os.ErrNotExist) {
+			r.mode = RecorderRecord
+		} else {
+			return nil, wrapJPushError(ErrorCodeInvalidParams, "failed to stat fixture file", err)
+		}
+	}
+
+	switch r.mode {
+	case RecorderReplay:
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+	case RecorderRecord:
+	default:
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("unsupported recorder mode: %s", mode))
+	}
+	return r, nil
+}
+
+// Mode 返回实际生效的模式，自动模式会解析为录制或回放
+func (r *Recorder) Mode() RecorderMode {
+	return r.mode
+}
+
+// Interactions 返回当前的录制记录
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Interaction(nil), r.interactions...)
+}
+
+// recorderTransport 单个客户端使用的录制回放传输层
+// 多个客户端共享同一个Recorder时，录制记录写入同一文件，但各自通过自己的传输层发送请求
+type recorderTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+// RoundTrip 实现http.RoundTripper
+func (t *recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.recorder.roundTrip(req, t.next)
+}
+
+// wrap 返回录制模式下通过next发送请求的传输层，不修改Recorder本身
+func (r *Recorder) wrap(next http.RoundTripper) http.RoundTripper {
+	return &recorderTransport{recorder: r, next: next}
+}
+
+// RoundTrip 实现http.RoundTripper，录制模式下通过http.DefaultTransport发送请求
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.roundTrip(req, nil)
+}
+
+// roundTrip 回放或录制请求，next为nil时使用http.DefaultTransport
+func (r *Recorder) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == RecorderReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body, next)
+}
+
+// replay 返回匹配的录制响应
+// 相同请求录制多次时按录制顺序依次返回，全部使用后重复返回最后一次的响应
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := req.URL.RequestURI()
+	last := -1
+	for i, in := range r.interactions {
+		if in.Request.Method != req.Method || in.Request.Path != path || !sameBody(in.Request.Body, body) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return in.Response.toHTTP(req), nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return r.interactions[last].Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoRecording, req.Method, path)
+}
+
+// record 通过next发出真实请求并保存录制记录
+func (r *Recorder) record(req *http.Request, body []byte, next http.RoundTripper) (*http.Response, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.RequestURI(),
+			Header: r.redactor.headers(req.Header),
+			Body:   fixtureBody(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     recordedResponseHeader(r.redactor.headers(resp.Header)),
+			Body:       fixtureBody(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, in)
+	r.used = append(r.used, true)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// load 读取夹具文件
+func (r *Recorder) load() error {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return wrapJPushError(ErrorCodeInvalidParams, "failed to read fixture file", err)
+	}
+	var f fixtureFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return wrapJPushError(ErrorCodeInvalidJSON, "failed to parse fixture file", err)
+	}
+	r.interactions = f.Interactions
+	r.used = make([]bool, len(f.Interactions))
+	return nil
+}
+
+// save 写入夹具文件，调用方需持有锁
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(fixtureFile{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return wrapJPushError(ErrorCodeInvalidJSON, "failed to encode fixture file", err)
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return wrapJPushError(ErrorCodeInvalidParams, "failed to create fixture directory", err)
+		}
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return wrapJPushError(ErrorCodeInvalidParams, "failed to write fixture file", err)
+	}
+	return nil
+}
+
+// toHTTP 构造回放响应
+func (resp *RecordedResponse) toHTTP(req *http.Request) *http.Response {
+	header := resp.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// recordedResponseHeader 去掉每次请求都会变化的响应头，保持夹具文件稳定
+func recordedResponseHeader(h http.Header) http.Header {
+	h = h.Clone()
+	h.Del("Date")
+	return h
+}
+
+// readRequestBody 读取并关闭请求体
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	return body, err
+}
+
+// sameBody 比较请求体，JSON内容忽略格式差异和字段顺序
+func sameBody(recorded, actual []byte) bool {
+	if bytes.Equal(recorded, actual) {
+		return true
+	}
+	var a, b interface{}
+	if json.Unmarshal(recorded, &a) != nil || json.Unmarshal(actual, &b) != nil {
+		return false
+	}
+	ra, _ := json.Marshal(a)
+	rb, _ := json.Marshal(b)
+	return bytes.Equal(ra, rb)
+}
@@ -0,0 +1,179 @@
+package goserversdk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRecorderClient(t *testing.T, baseURL string, recorder *Recorder) *Client {
+	t.Helper()
+	client, err := NewClient(&Config{
+		AppKey:       "test-app-key",
+		MasterSecret: "test-master-secret",
+		BaseURLs:     &BaseURLs{Push: baseURL, Report: baseURL},
+		Recorder:     recorder,
+	})
+	assert.NoError(t, err)
+	return client
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"sendno":"1","msg_id":"18100000012345678"}`))
+	}))
+
+	fixture := filepath.Join(t.TempDir(), "testdata", "push.json")
+	recorder, err := NewRecorder(fixture, RecorderAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, RecorderRecord, recorder.Mode())
+
+	req := &PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewRegistrationIDAudience("reg1"),
+		Notification: &Notification{Alert: "hello"},
+	}
+	resp, err := newRecorderClient(t, server.URL, recorder).Push.Push(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "18100000012345678", resp.MsgID)
+	server.Close()
+
+	// 夹具中的认证信息已脱敏，请求体以JSON保存
+	data, err := os.ReadFile(fixture)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "test-master-secret")
+	assert.NotContains(t, string(data), "session=secret")
+	assert.NotContains(t, string(data), `"Date"`)
+	assert.Contains(t, string(data), `"Basic ***"`)
+	assert.Contains(t, string(data), `"path": "/v3/push"`)
+	assert.Contains(t, string(data), `"alert": "hello"`)
+
+	// 服务关闭后回放
+	replayer, err := NewRecorder(fixture, RecorderAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, RecorderReplay, replayer.Mode())
+	client := newRecorderClient(t, "https://api.example.com", replayer)
+
+	for i := 0; i < 2; i++ {
+		resp, err = client.Push.Push(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "18100000012345678", resp.MsgID)
+	}
+	assert.Equal(t, 1, hits)
+
+	// 请求体不同则不匹配
+	req.Notification.Alert = "other"
+	_, err = client.Push.Push(req)
+	assert.True(t, errors.Is(err, ErrNoRecording))
+}
+
+// countingTransport 统计经过的请求数
+type countingTransport struct {
+	count int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.count++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRecorder_SharedBetweenClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sendno":"1","msg_id":"2"}`))
+	}))
+	defer server.Close()
+
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "shared.json"), RecorderRecord)
+	assert.NoError(t, err)
+
+	// 两个客户端共享Recorder，各自的请求经过自己的传输层
+	newClient := func(transport http.RoundTripper) *Client {
+		client, err := NewClient(&Config{
+			AppKey:       "test-app-key",
+			MasterSecret: "test-master-secret",
+			BaseURLs:     &BaseURLs{Push: server.URL},
+			Transport:    transport,
+			Recorder:     recorder,
+		})
+		assert.NoError(t, err)
+		return client
+	}
+	first, second := &countingTransport{}, &countingTransport{}
+	a, b := newClient(first), newClient(second)
+
+	req := &PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewBroadcastAudience(),
+		Notification: &Notification{Alert: "hello"},
+	}
+	_, err = a.Push.Push(req)
+	assert.NoError(t, err)
+	_, err = a.Push.Push(req)
+	assert.NoError(t, err)
+	_, err = b.Push.Push(req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, first.count)
+	assert.Equal(t, 1, second.count)
+	assert.Len(t, recorder.Interactions(), 3)
+}
+
+func TestRecorder_ReplaySequence(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "status.json")
+	content := `{"interactions":[
+		{"request":{"method":"POST","path":"/v3/status/message","body":{"registration_ids":["a"],"msg_id":1}},
+		 "response":{"status_code":200,"body":{"a":{"status":1}}}},
+		{"request":{"method":"POST","path":"/v3/status/message","body":{"msg_id":1,"registration_ids":["a"]}},
+		 "response":{"status_code":200,"body":{"a":{"status":0}}}},
+		{"request":{"method":"GET","path":"/v3/push/cid?count=1"},
+		 "response":{"status_code":429,"header":{"X-Rate-Limit-Limit":["600"],"X-Rate-Limit-Remaining":["0"],"X-Rate-Limit-Reset":["5"]},"body":"{\"error\":{\"code\":2002,\"message\":\"limited\"}}"}}
+	]}`
+	assert.NoError(t, os.WriteFile(fixture, []byte(content), 0o644))
+
+	recorder, err := NewRecorder(fixture, RecorderReplay)
+	assert.NoError(t, err)
+	client := newRecorderClient(t, "https://report.example.com", recorder)
+
+	// 相同请求按录制顺序返回，字段顺序不影响匹配
+	statusReq := &MessageStatusRequest{MsgID: "1", RegistrationIDs: []string{"a"}}
+	status, err := client.Report.GetMessageStatus(statusReq)
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryStatusNotDelivered, status["a"].Status)
+	status, err = client.Report.GetMessageStatus(statusReq)
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryStatusDelivered, status["a"].Status)
+	status, err = client.Report.GetMessageStatus(statusReq)
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryStatusDelivered, status["a"].Status)
+
+	_, err = client.Advanced.GetCID(1, "")
+	var jerr *JPushError
+	assert.True(t, errors.As(err, &jerr))
+	assert.Equal(t, ErrorCodeRateLimitExceeded, jerr.Code)
+	assert.Equal(t, 5, jerr.RateLimit.Reset)
+}
+
+func TestNewRecorder_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewRecorder(filepath.Join(dir, "missing.json"), RecorderReplay)
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+
+	bad := filepath.Join(dir, "bad.json")
+	assert.NoError(t, os.WriteFile(bad, []byte("not json"), 0o644))
+	_, err = NewRecorder(bad, RecorderReplay)
+	assert.Equal(t, ErrorCodeInvalidJSON, GetErrorCode(err))
+
+	_, err = NewRecorder(bad, RecorderMode(9))
+	assert.True(t, strings.Contains(err.Error(), "RecorderMode(9)"))
+}
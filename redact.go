@@ -0,0 +1,171 @@
+package goserversdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RedactionPolicy 日志脱敏策略
+type RedactionPolicy int
+
+const (
+	RedactionMasked RedactionPolicy = iota // 部分遮盖敏感值，保留首尾字符便于排查（默认）
+	RedactionFull                          // 完全隐藏敏感值
+	RedactionOff                           // 不脱敏，仅用于本地调试
+)
+
+// redactedPlaceholder 被隐藏的值
+const redactedPlaceholder = "***"
+
+// defaultRedactKeys 默认需要脱敏的JSON字段
+var defaultRedactKeys = []string{
+	"registration_id",
+	"registration_ids",
+	"alias",
+	"mobile",
+	"mobile_number",
+	"phone",
+}
+
+// sensitiveHeaders 需要脱敏的请求头与响应头
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// redactor 按策略对日志中的请求头、请求体和响应体进行脱敏
+type redactor struct {
+	policy RedactionPolicy
+	keys   map[string]bool
+}
+
+// newRedactor 创建脱敏器，extraKeys为额外需要脱敏的JSON字段（如extras中的自定义字段）
+func newRedactor(policy RedactionPolicy, extraKeys []string) *redactor {
+	keys := make(map[string]bool, len(defaultRedactKeys)+len(extraKeys))
+	for _, k := range defaultRedactKeys {
+		keys[k] = true
+	}
+	for _, k := range extraKeys {
+		keys[strings.ToLower(k)] = true
+	}
+	return &redactor{policy: policy, keys: keys}
+}
+
+// mask 按策略遮盖单个值
+func (r *redactor) mask(s string) string {
+	if r.policy == RedactionOff {
+		return s
+	}
+	// 按字符而非字节截取，避免中文等多字节字符被截断
+	runes := []rune(s)
+	if r.policy == RedactionFull || len(runes) <= 6 {
+		return redactedPlaceholder
+	}
+	return string(runes[:2]) + redactedPlaceholder + string(runes[len(runes)-2:])
+}
+
+// headers 返回脱敏后的请求头副本
+func (r *redactor) headers(h http.Header) http.Header {
+	if r.policy == RedactionOff {
+		return h
+	}
+	out := h.Clone()
+	for _, name := range sensitiveHeaders {
+		values := out.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, v := range values {
+			// 保留认证方式，如 "Basic ***"
+			if scheme, _, ok := strings.Cut(v, " "); ok && name != "Cookie" && name != "Set-Cookie" {
+				masked[i] = scheme + " " + redactedPlaceholder
+			} else {
+				masked[i] = redactedPlaceholder
+			}
+		}
+		out[http.CanonicalHeaderKey(name)] = masked
+	}
+	return out
+}
+
+// body 返回脱敏后的JSON文本，非JSON内容只输出长度
+func (r *redactor) body(data []byte) string {
+	if r.policy == RedactionOff || len(data) == 0 {
+		return string(data)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Sprintf("[%d bytes non-JSON body]", len(data))
+	}
+
+	out, err := json.Marshal(r.walk(v, false))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes body]", len(data))
+	}
+	return string(out)
+}
+
+// errorBody 返回脱敏后的响应体，用于保存到JPushError；非JSON内容原样返回以便排查
+func (r *redactor) errorBody(data []byte) []byte {
+	if r.policy == RedactionOff || len(data) == 0 {
+		return data
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return data
+	}
+	out, err := json.Marshal(r.walk(v, false))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// value 将任意值序列化为JSON后脱敏，用于记录请求结构体
+func (r *redactor) value(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("[unserializable %T]", v)
+	}
+	return r.body(data)
+}
+
+// walk 递归遮盖敏感字段，sensitive表示当前值位于敏感字段下
+func (r *redactor) walk(v interface{}, sensitive bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = r.walk(child, sensitive || r.keys[strings.ToLower(k)])
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = r.walk(child, sensitive)
+		}
+		return val
+	case string:
+		if sensitive {
+			return r.mask(val)
+		}
+		return val
+	case json.Number:
+		if sensitive {
+			return r.mask(val.String())
+		}
+		return val
+	default:
+		return val
+	}
+}
@@ -0,0 +1,143 @@
+package goserversdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Headers(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Basic YXBwOnNlY3JldA==")
+	h.Set("Content-Type", "application/json")
+
+	masked := newRedactor(RedactionMasked, nil).headers(h)
+	assert.Equal(t, "Basic ***", masked.Get("Authorization"))
+	assert.Equal(t, "application/json", masked.Get("Content-Type"))
+	// 原始请求头不受影响
+	assert.Equal(t, "Basic YXBwOnNlY3JldA==", h.Get("Authorization"))
+
+	off := newRedactor(RedactionOff, nil).headers(h)
+	assert.Equal(t, "Basic YXBwOnNlY3JldA==", off.Get("Authorization"))
+}
+
+func TestRedactor_Body(t *testing.T) {
+	body := []byte(`{"audience":{"registration_id":["1a0018970a8b2c3d"],"alias":["alice"],"tag":["vip"]},` +
+		`"sms_message":{"mobile":"13800138000"},"notification":{"android":{"extras":{"user_email":"a@example.com","page":"home"}}}}`)
+
+	masked := newRedactor(RedactionMasked, []string{"user_email"}).body(body)
+	assert.Contains(t, masked, `"registration_id":["1a***3d"]`)
+	assert.Contains(t, masked, `"alias":["***"]`)
+	assert.Contains(t, masked, `"tag":["vip"]`)
+	assert.Contains(t, masked, `"mobile":"13***00"`)
+	assert.Contains(t, masked, `"user_email":"a@***om"`)
+	assert.Contains(t, masked, `"page":"home"`)
+
+	full := newRedactor(RedactionFull, nil).body(body)
+	assert.Contains(t, full, `"registration_id":["***"]`)
+	assert.Contains(t, full, `"mobile":"***"`)
+
+	assert.Equal(t, string(body), newRedactor(RedactionOff, nil).body(body))
+	assert.Equal(t, "[9 bytes non-JSON body]", newRedactor(RedactionMasked, nil).body([]byte("not json!")))
+}
+
+func TestRedactor_MaskMultibyte(t *testing.T) {
+	r := newRedactor(RedactionMasked, []string{"tag"})
+	assert.Equal(t, "张三***六七", r.mask("张三李四王五六七"))
+	assert.Equal(t, "***", r.mask("北京朝阳区"))
+
+	masked := r.body([]byte(`{"alias":["王小明的测试设备"],"tag":["北京市海淀区用户"]}`))
+	assert.True(t, utf8.ValidString(masked))
+	assert.Contains(t, masked, `"王小***设备"`)
+	assert.Contains(t, masked, `"北京***用户"`)
+}
+
+func TestClient_RedactsErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"code":1011,"message":"cannot find user by this audience"},"audience":{"registration_id":["1a0018970a8b2c3d"]}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: server.URL},
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(&PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewRegistrationIDAudience("1a0018970a8b2c3d"),
+		Notification: &Notification{Alert: "hello"},
+	})
+	var jpushErr *JPushError
+	if assert.ErrorAs(t, err, &jpushErr) {
+		assert.Equal(t, ErrorCodeNoValidTarget, jpushErr.Code)
+		assert.NotContains(t, jpushErr.Body, "1a0018970a8b2c3d")
+		assert.Contains(t, jpushErr.Body, "1a***3d")
+	}
+}
+
+func TestRedactor_Value(t *testing.T) {
+	req := &PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewRegistrationIDAudience("1a0018970a8b2c3d"),
+		Notification: &Notification{Alert: "hello"},
+	}
+	out := newRedactor(RedactionMasked, nil).value(req)
+	assert.NotContains(t, out, "1a0018970a8b2c3d")
+	assert.Contains(t, out, "hello")
+}
+
+func TestClient_RedactsLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sendno":"1","msg_id":"2"}`))
+	}))
+	defer server.Close()
+
+	rec := &capturingLogger{}
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		LogAdapter:   rec,
+		BaseURLs:     &BaseURLs{Push: server.URL},
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(&PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewAliasAudience("alice-secret-alias"),
+		Notification: &Notification{Alert: "hello"},
+	})
+	assert.NoError(t, err)
+
+	logged := rec.String()
+	assert.NotContains(t, logged, "alice-secret-alias")
+	assert.NotContains(t, logged, "YXBwLWtleTptYXN0ZXItc2VjcmV0") // base64("app-key:master-secret")
+	assert.Contains(t, logged, "Basic ***")
+}
+
+// capturingLogger 将所有日志的键值对拼接为文本，便于断言日志内容
+type capturingLogger struct {
+	strings.Builder
+}
+
+func (c *capturingLogger) log(msg string, keysAndValues []interface{}) {
+	c.WriteString(msg)
+	for _, kv := range keysAndValues {
+		c.WriteString(" ")
+		c.WriteString(fmt.Sprint(kv))
+	}
+	c.WriteString("\n")
+}
+
+func (c *capturingLogger) Debug(msg string, kv ...interface{}) { c.log(msg, kv) }
+func (c *capturingLogger) Info(msg string, kv ...interface{})  { c.log(msg, kv) }
+func (c *capturingLogger) Warn(msg string, kv ...interface{})  { c.log(msg, kv) }
+func (c *capturingLogger) Error(msg string, kv ...interface{}) { c.log(msg, kv) }
@@ -1,15 +1,20 @@
 package goserversdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ReportService 统计服务
 type ReportService struct {
 	client *Client
+	logger Logger
 }
 
 // ReceivedDetailResponse 送达统计详情响应
@@ -32,6 +37,20 @@ type ReceivedDetailResponse struct {
 	HMOSMsgSent             *int   `json:"hmos_msg_sent"`              // 鸿蒙自定义消息推送到厂商服务器成功数
 }
 
+// UnmarshalJSON 兼容字符串和数字形式的msg_id
+func (r *ReceivedDetailResponse) UnmarshalJSON(data []byte) error {
+	type alias ReceivedDetailResponse
+	aux := struct {
+		*alias
+		MsgID flexibleID `json:"msg_id"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.MsgID = string(aux.MsgID)
+	return nil
+}
+
 // ReceivedResponse 送达统计响应（旧接口）
 type ReceivedResponse struct {
 	MsgID             string `json:"msg_id"`               // 消息ID
@@ -42,19 +61,112 @@ type ReceivedResponse struct {
 	WPMPNSSent        *int   `json:"wp_mpns_sent"`         // WP推送数
 }
 
+// UnmarshalJSON 兼容字符串和数字形式的msg_id
+func (r *ReceivedResponse) UnmarshalJSON(data []byte) error {
+	type alias ReceivedResponse
+	aux := struct {
+		*alias
+		MsgID flexibleID `json:"msg_id"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.MsgID = string(aux.MsgID)
+	return nil
+}
+
 // MessageStatusRequest 送达状态查询请求
 type MessageStatusRequest struct {
-	MsgID           int64    `json:"msg_id"`            // 消息ID
+	MsgID           string   `json:"msg_id"`            // 消息ID，与其他接口一致使用字符串，请求时以数字形式发送
 	RegistrationIDs []string `json:"registration_ids"`  // 设备注册ID列表，最多1000个
 	Date            string   `json:"date,omitempty"`    // 查询日期，格式yyyy-mm-dd，默认当天
 }
 
+// messageStatusRequestJSON MessageStatusRequest的传输格式，msg_id为数字
+type messageStatusRequestJSON struct {
+	MsgID           json.Number `json:"msg_id"`
+	RegistrationIDs []string    `json:"registration_ids"`
+	Date            string      `json:"date,omitempty"`
+}
+
+// MarshalJSON 将msg_id以数字形式序列化
+func (r MessageStatusRequest) MarshalJSON() ([]byte, error) {
+	if _, err := strconv.ParseInt(r.MsgID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid msg_id %q: %w", r.MsgID, err)
+	}
+	return json.Marshal(messageStatusRequestJSON{
+		MsgID:           json.Number(r.MsgID),
+		RegistrationIDs: r.RegistrationIDs,
+		Date:            r.Date,
+	})
+}
+
+// UnmarshalJSON 兼容数字和字符串形式的msg_id
+func (r *MessageStatusRequest) UnmarshalJSON(data []byte) error {
+	var raw messageStatusRequestJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.MsgID = raw.MsgID.String()
+	r.RegistrationIDs = raw.RegistrationIDs
+	r.Date = raw.Date
+	return nil
+}
+
 // MessageStatusResponse 送达状态查询响应
 type MessageStatusResponse map[string]MessageStatus
 
 // MessageStatus 消息状态
 type MessageStatus struct {
-	Status int `json:"status"` // 状态：0-送达，1-未送达，2-registration_id不属于该应用，3-不是该条message的推送目标，4-系统异常
+	Status DeliveryStatus `json:"status"` // 送达状态
+}
+
+// DeliveryStatus 单个设备的消息送达状态
+type DeliveryStatus int
+
+const (
+	DeliveryStatusDelivered             DeliveryStatus = 0 // 送达
+	DeliveryStatusNotDelivered          DeliveryStatus = 1 // 未送达
+	DeliveryStatusInvalidRegistrationID DeliveryStatus = 2 // registration_id不属于该应用
+	DeliveryStatusNotTarget             DeliveryStatus = 3 // 不是该条message的推送目标
+	DeliveryStatusSystemError           DeliveryStatus = 4 // 系统异常
+)
+
+func (s DeliveryStatus) String() string {
+	switch s {
+	case DeliveryStatusDelivered:
+		return "delivered"
+	case DeliveryStatusNotDelivered:
+		return "not_delivered"
+	case DeliveryStatusInvalidRegistrationID:
+		return "invalid_registration_id"
+	case DeliveryStatusNotTarget:
+		return "not_target"
+	case DeliveryStatusSystemError:
+		return "system_error"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// IsDelivered 消息是否已送达
+func (s DeliveryStatus) IsDelivered() bool {
+	return s == DeliveryStatusDelivered
+}
+
+// IsNotDelivered 消息是否尚未送达
+func (s DeliveryStatus) IsNotDelivered() bool {
+	return s == DeliveryStatusNotDelivered
+}
+
+// IsInvalidTarget 设备是否不属于该应用或不是该消息的推送目标
+func (s DeliveryStatus) IsInvalidTarget() bool {
+	return s == DeliveryStatusInvalidRegistrationID || s == DeliveryStatusNotTarget
+}
+
+// IsSystemError 查询是否因系统异常失败，可稍后重试
+func (s DeliveryStatus) IsSystemError() bool {
+	return s == DeliveryStatusSystemError
 }
 
 // ChannelStats 通道统计数据
@@ -152,6 +264,20 @@ type MessageDetailResponse struct {
 	Details *MessageDetailStats `json:"details"` // 详细统计数据
 }
 
+// UnmarshalJSON 兼容字符串和数字形式的msg_id
+func (r *MessageDetailResponse) UnmarshalJSON(data []byte) error {
+	type alias MessageDetailResponse
+	aux := struct {
+		*alias
+		MsgID flexibleID `json:"msg_id"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.MsgID = string(aux.MsgID)
+	return nil
+}
+
 // GetReceivedDetail 获取送达统计详情
 // msgIDs: 消息ID列表，最多支持100个
 func (s *ReportService) GetReceivedDetail(msgIDs []string) ([]ReceivedDetailResponse, error) {
@@ -163,21 +289,26 @@ func (s *ReportService) GetReceivedDetail(msgIDs []string) ([]ReceivedDetailResp
 		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_ids cannot exceed 100")
 	}
 
-	url := fmt.Sprintf("/v3/received/detail?msg_ids=%s", strings.Join(msgIDs, ","))
+	receivedResp, _, err := s.getReceivedDetail(context.Background(), msgIDs)
+	return receivedResp, err
+}
+
+// getReceivedDetail 请求送达统计详情，同时返回原始响应以便读取频率限制信息
+func (s *ReportService) getReceivedDetail(ctx context.Context, msgIDs []string) ([]ReceivedDetailResponse, *APIResponse, error) {
+	path := buildPath("/v3/received/detail", url.Values{"msg_ids": {strings.Join(msgIDs, ",")}})
 	
 	// 使用report域名
-	resp, err := s.client.makeReportRequest(http.MethodGet, url, nil)
+	resp, err := s.client.makeReportRequestContext(ctx, http.MethodGet, path, nil)
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
 
-	var receivedResp []ReceivedDetailResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &receivedResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse received detail response: %v", err))
+	receivedResp, err := decodeResponseList[ReceivedDetailResponse](resp)
+	if err != nil {
+		return nil, resp, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse received detail response", err)
 	}
 
-	return receivedResp, nil
+	return receivedResp, resp, nil
 }
 
 // GetReceived 获取送达统计（旧接口）
@@ -191,59 +322,214 @@ func (s *ReportService) GetReceived(msgIDs []string) ([]ReceivedResponse, error)
 		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_ids cannot exceed 100")
 	}
 
-	url := fmt.Sprintf("/v3/received?msg_ids=%s", strings.Join(msgIDs, ","))
+	receivedResp, _, err := s.getReceived(context.Background(), msgIDs)
+	return receivedResp, err
+}
+
+// getReceived 请求送达统计（旧接口），同时返回原始响应以便读取频率限制信息
+func (s *ReportService) getReceived(ctx context.Context, msgIDs []string) ([]ReceivedResponse, *APIResponse, error) {
+	path := buildPath("/v3/received", url.Values{"msg_ids": {strings.Join(msgIDs, ",")}})
 	
 	// 使用report域名
-	resp, err := s.client.makeReportRequest(http.MethodGet, url, nil)
+	resp, err := s.client.makeReportRequestContext(ctx, http.MethodGet, path, nil)
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
 
-	var receivedResp []ReceivedResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &receivedResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse received response: %v", err))
+	receivedResp, err := decodeResponseList[ReceivedResponse](resp)
+	if err != nil {
+		return nil, resp, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse received response", err)
 	}
 
-	return receivedResp, nil
+	return receivedResp, resp, nil
 }
 
 // GetMessageStatus 查询消息送达状态（VIP功能）
 func (s *ReportService) GetMessageStatus(req *MessageStatusRequest) (MessageStatusResponse, error) {
+	if err := validateMessageStatusRequest(req); err != nil {
+		return nil, err
+	}
+
+	if len(req.RegistrationIDs) > maxStatusRegistrationIDs {
+		return nil, NewJPushError(ErrorCodeInvalidParams, "registration_ids cannot exceed 1000")
+	}
+
+	statusResp, _, err := s.getMessageStatus(context.Background(), req)
+	return statusResp, err
+}
+
+// getMessageStatus 请求消息送达状态，同时返回原始响应以便读取频率限制信息
+func (s *ReportService) getMessageStatus(ctx context.Context, req *MessageStatusRequest) (MessageStatusResponse, *APIResponse, error) {
+	// 使用report域名
+	resp, err := s.client.makeReportRequestContext(ctx, http.MethodPost, "/v3/status/message", req)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var statusResp MessageStatusResponse
+	if err := resp.Decode(&statusResp); err != nil {
+		return nil, resp, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse message status response", err)
+	}
+
+	return statusResp, resp, nil
+}
+
+// validateMessageStatusRequest 验证送达状态查询请求，不检查registration_ids数量上限
+func validateMessageStatusRequest(req *MessageStatusRequest) error {
 	if req == nil {
-		return nil, NewJPushError(ErrorCodeInvalidParams, "request cannot be nil")
+		return NewJPushError(ErrorCodeInvalidParams, "request cannot be nil")
+	}
+
+	if req.MsgID == "" {
+		return NewJPushError(ErrorCodeInvalidParams, "msg_id is required")
 	}
 
-	if req.MsgID == 0 {
-		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_id is required")
+	if id, err := strconv.ParseInt(req.MsgID, 10, 64); err != nil || id <= 0 {
+		return NewJPushError(ErrorCodeInvalidParams, "msg_id must be a positive integer")
 	}
 
 	if len(req.RegistrationIDs) == 0 {
-		return nil, NewJPushError(ErrorCodeInvalidParams, "registration_ids cannot be empty")
+		return NewJPushError(ErrorCodeInvalidParams, "registration_ids cannot be empty")
 	}
 
-	if len(req.RegistrationIDs) > 1000 {
-		return nil, NewJPushError(ErrorCodeInvalidParams, "registration_ids cannot exceed 1000")
+	return nil
+}
+
+// GetMessageDetail 获取消息统计详情（VIP功能）
+// msgIDs: 消息ID列表，最多支持100个
+func (s *ReportService) GetMessageDetail(msgIDs []string) ([]MessageDetailResponse, error) {
+	if len(msgIDs) == 0 {
+		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_ids cannot be empty")
+	}
+
+	if len(msgIDs) > 100 {
+		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_ids cannot exceed 100")
 	}
 
+	ctx, span := s.client.startSpan(context.Background(), "jpush.GetMessageDetail")
+	span.SetAttribute(AttrEndpoint, "/v3/messages/detail")
+	span.SetAttribute(AttrMsgIDCount, len(msgIDs))
+	if len(msgIDs) == 1 {
+		span.SetAttribute(AttrMsgID, msgIDs[0])
+	}
+
+	detailResp, _, err := s.getMessageDetail(ctx, msgIDs)
+	endSpan(span, err)
+	return detailResp, err
+}
+
+// getMessageDetail 请求消息统计详情，同时返回原始响应以便读取频率限制信息，ctx用于传递追踪信息和取消请求
+func (s *ReportService) getMessageDetail(ctx context.Context, msgIDs []string) ([]MessageDetailResponse, *APIResponse, error) {
+	path := buildPath("/v3/messages/detail", url.Values{"msg_ids": {strings.Join(msgIDs, ",")}})
+	
 	// 使用report域名
-	resp, err := s.client.makeReportRequest(http.MethodPost, "/v3/status/message", req)
+	resp, err := s.client.makeReportRequestContext(ctx, http.MethodGet, path, nil)
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
 
-	var statusResp MessageStatusResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &statusResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse message status response: %v", err))
+	detailResp, err := decodeResponseList[MessageDetailResponse](resp)
+	if err != nil {
+		return nil, resp, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse message detail response", err)
 	}
 
-	return statusResp, nil
+	return detailResp, resp, nil
 }
 
-// GetMessageDetail 获取消息统计详情（VIP功能）
+// AndroidMessageStats Android平台消息统计
+type AndroidMessageStats struct {
+	Received   *int `json:"received"`    // 送达数
+	Target     *int `json:"target"`      // 推送目标数
+	OnlinePush *int `json:"online_push"` // 在线推送数
+	Click      *int `json:"click"`       // 通知点击数
+	MsgClick   *int `json:"msg_click"`   // 自定义消息点击数
+}
+
+// IOSMessageStats iOS平台消息统计
+type IOSMessageStats struct {
+	APNSSent     *int `json:"apns_sent"`     // APNs通知推送成功数
+	APNSTarget   *int `json:"apns_target"`   // APNs通知推送目标数
+	APNSReceived *int `json:"apns_received"` // APNs通知送达数
+	Click        *int `json:"click"`         // 通知点击数
+	Target       *int `json:"target"`        // 自定义消息推送目标数
+	Received     *int `json:"received"`      // 自定义消息送达数
+	MsgClick     *int `json:"msg_click"`     // 自定义消息点击数
+}
+
+// WinPhoneMessageStats WinPhone平台消息统计
+type WinPhoneMessageStats struct {
+	MPNSTarget *int `json:"mpns_target"` // MPNs推送目标数
+	MPNSSent   *int `json:"mpns_sent"`   // MPNs推送成功数
+	Click      *int `json:"click"`       // 通知点击数
+}
+
+// MessagesResponse 消息统计响应
+type MessagesResponse struct {
+	MsgID    string                `json:"msg_id"`             // 消息ID
+	Android  *AndroidMessageStats  `json:"android,omitempty"`  // Android统计
+	IOS      *IOSMessageStats      `json:"ios,omitempty"`      // iOS统计
+	WinPhone *WinPhoneMessageStats `json:"winphone,omitempty"` // WinPhone统计
+}
+
+// UnmarshalJSON 兼容字符串和数字形式的msg_id
+func (r *MessagesResponse) UnmarshalJSON(data []byte) error {
+	type alias MessagesResponse
+	aux := struct {
+		*alias
+		MsgID flexibleID `json:"msg_id"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.MsgID = string(aux.MsgID)
+	return nil
+}
+
+// TimeUnit 统计时间单位
+type TimeUnit string
+
+const (
+	TimeUnitHour  TimeUnit = "HOUR"
+	TimeUnitDay   TimeUnit = "DAY"
+	TimeUnitMonth TimeUnit = "MONTH"
+)
+
+// 各时间单位下允许的最大持续时长及起始时间格式
+var timeUnitRules = map[TimeUnit]struct {
+	maxDuration int
+	layout      string
+}{
+	TimeUnitHour:  {maxDuration: 24, layout: "2006-01-02 15"},
+	TimeUnitDay:   {maxDuration: 60, layout: "2006-01-02"},
+	TimeUnitMonth: {maxDuration: 2, layout: "2006-01"},
+}
+
+// PlatformUserStats 平台用户统计
+type PlatformUserStats struct {
+	New    *int `json:"new"`    // 新增用户数
+	Online *int `json:"online"` // 在线用户数
+	Active *int `json:"active"` // 活跃用户数
+}
+
+// UserStatsItem 单个时间段的用户统计
+type UserStatsItem struct {
+	Time     string             `json:"time"`               // 统计时间段
+	Android  *PlatformUserStats `json:"android,omitempty"`  // Android统计
+	IOS      *PlatformUserStats `json:"ios,omitempty"`      // iOS统计
+	WinPhone *PlatformUserStats `json:"winphone,omitempty"` // WinPhone统计
+}
+
+// UserStatsResponse 用户统计响应
+type UserStatsResponse struct {
+	TimeUnit TimeUnit        `json:"time_unit"` // 时间单位
+	Start    string          `json:"start"`     // 起始时间
+	Duration int             `json:"duration"`  // 持续时长
+	Items    []UserStatsItem `json:"items"`     // 按时间单位聚合的统计数据
+}
+
+// GetMessages 获取消息统计
 // msgIDs: 消息ID列表，最多支持100个
-func (s *ReportService) GetMessageDetail(msgIDs []string) ([]MessageDetailResponse, error) {
+func (s *ReportService) GetMessages(msgIDs []string) ([]MessagesResponse, error) {
 	if len(msgIDs) == 0 {
 		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_ids cannot be empty")
 	}
@@ -252,19 +538,62 @@ func (s *ReportService) GetMessageDetail(msgIDs []string) ([]MessageDetailRespon
 		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_ids cannot exceed 100")
 	}
 
-	url := fmt.Sprintf("/v3/messages/detail?msg_ids=%s", strings.Join(msgIDs, ","))
-	
+	messagesResp, _, err := s.getMessages(context.Background(), msgIDs)
+	return messagesResp, err
+}
+
+// getMessages 请求消息统计，同时返回原始响应以便读取频率限制信息
+func (s *ReportService) getMessages(ctx context.Context, msgIDs []string) ([]MessagesResponse, *APIResponse, error) {
+	path := buildPath("/v3/messages", url.Values{"msg_ids": {strings.Join(msgIDs, ",")}})
+
+	// 使用report域名
+	resp, err := s.client.makeReportRequestContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	messagesResp, err := decodeResponseList[MessagesResponse](resp)
+	if err != nil {
+		return nil, resp, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse messages response", err)
+	}
+
+	return messagesResp, resp, nil
+}
+
+// GetUserStats 获取按时间单位聚合的用户统计
+// timeUnit: 时间单位，HOUR/DAY/MONTH
+// start: 起始时间，格式分别为yyyy-mm-dd hh、yyyy-mm-dd、yyyy-mm
+// duration: 持续时长，HOUR最多24，DAY最多60，MONTH最多2
+func (s *ReportService) GetUserStats(timeUnit TimeUnit, start string, duration int) (*UserStatsResponse, error) {
+	rule, ok := timeUnitRules[timeUnit]
+	if !ok {
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("unsupported time_unit: %s", timeUnit))
+	}
+
+	if _, err := time.Parse(rule.layout, start); err != nil {
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("start must match format %q for time_unit %s", rule.layout, timeUnit))
+	}
+
+	if duration <= 0 || duration > rule.maxDuration {
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("duration must be in [1,%d] for time_unit %s", rule.maxDuration, timeUnit))
+	}
+
+	path := buildPath("/v3/users", url.Values{
+		"time_unit": {string(timeUnit)},
+		"start":     {start},
+		"duration":  {strconv.Itoa(duration)},
+	})
+
 	// 使用report域名
-	resp, err := s.client.makeReportRequest(http.MethodGet, url, nil)
+	resp, err := s.client.makeReportRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var detailResp []MessageDetailResponse
-	bodyBytes, _ := json.Marshal(resp.Body)
-	if err := json.Unmarshal(bodyBytes, &detailResp); err != nil {
-		return nil, NewJPushError(ErrorCodeInvalidJSON, fmt.Sprintf("failed to parse message detail response: %v", err))
+	var statsResp UserStatsResponse
+	if err := resp.Decode(&statsResp); err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidJSON, "failed to parse user stats response", err)
 	}
 
-	return detailResp, nil
-}
\ No newline at end of file
+	return &statsResp, nil
+}
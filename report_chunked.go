@@ -0,0 +1,310 @@
+package goserversdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxReportMsgIDs          = 100  // 统计接口单次请求允许的最大msg_id数量
+	maxStatusRegistrationIDs = 1000 // 送达状态查询单次请求允许的最大registration_id数量
+	defaultChunkConcurrency  = 4    // 分批查询默认并发数
+	defaultChunkMaxRetries   = 2    // 触发频率限制时的默认重试次数
+)
+
+// chunkRetryDelay 频率限制响应未携带X-Rate-Limit-Reset时的默认等待时间
+var chunkRetryDelay = time.Second
+
+// ChunkOptions 分批查询选项
+type ChunkOptions struct {
+	ChunkSize   int // 每批ID数量，默认且最大为接口单次上限（msg_id为100，registration_id为1000）
+	Concurrency int // 最大并发请求数，默认4
+	MaxRetries  int // 触发频率限制时每批的最大重试次数，默认2，小于0表示不重试
+}
+
+// ChunkFailure 单个分批请求的失败信息
+type ChunkFailure struct {
+	Index int      // 分批序号，从0开始
+	IDs   []string // 该批次包含的msg_id或registration_id
+	Err   error    // 失败原因
+}
+
+// ChunkedError 分批查询中部分批次失败时返回的错误
+// 成功批次的结果仍会正常返回
+type ChunkedError struct {
+	Total    int            // 总批次数
+	Failures []ChunkFailure // 失败的批次，按序号升序排列
+}
+
+func (e *ChunkedError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		parts = append(parts, fmt.Sprintf("chunk %d: %v", f.Index, f.Err))
+	}
+	return fmt.Sprintf("%d of %d chunks failed: %s", len(e.Failures), e.Total, strings.Join(parts, "; "))
+}
+
+// Unwrap 返回各失败批次的错误，使errors.Is/errors.As可以匹配其中任意一个
+func (e *ChunkedError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// FailedIDs 返回所有失败批次包含的ID
+func (e *ChunkedError) FailedIDs() []string {
+	var ids []string
+	for _, f := range e.Failures {
+		ids = append(ids, f.IDs...)
+	}
+	return ids
+}
+
+// GetReceivedDetailChunked 分批获取送达统计详情，msg_id数量不受100个的限制
+// opts为nil时使用默认选项；部分批次失败时返回成功批次的结果以及*ChunkedError
+func (s *ReportService) GetReceivedDetailChunked(msgIDs []string, opts *ChunkOptions) ([]ReceivedDetailResponse, error) {
+	return s.GetReceivedDetailChunkedContext(context.Background(), msgIDs, opts)
+}
+
+// GetReceivedDetailChunkedContext 同GetReceivedDetailChunked，ctx取消后不再发送新的批次
+func (s *ReportService) GetReceivedDetailChunkedContext(ctx context.Context, msgIDs []string, opts *ChunkOptions) ([]ReceivedDetailResponse, error) {
+	return runChunked(ctx, s.client, msgIDs, opts, s.getReceivedDetail)
+}
+
+// GetReceivedChunked 分批获取送达统计（旧接口），msg_id数量不受100个的限制
+// opts为nil时使用默认选项；部分批次失败时返回成功批次的结果以及*ChunkedError
+func (s *ReportService) GetReceivedChunked(msgIDs []string, opts *ChunkOptions) ([]ReceivedResponse, error) {
+	return s.GetReceivedChunkedContext(context.Background(), msgIDs, opts)
+}
+
+// GetReceivedChunkedContext 同GetReceivedChunked，ctx取消后不再发送新的批次
+func (s *ReportService) GetReceivedChunkedContext(ctx context.Context, msgIDs []string, opts *ChunkOptions) ([]ReceivedResponse, error) {
+	return runChunked(ctx, s.client, msgIDs, opts, s.getReceived)
+}
+
+// GetMessageDetailChunked 分批获取消息统计详情（VIP功能），msg_id数量不受100个的限制
+// opts为nil时使用默认选项；部分批次失败时返回成功批次的结果以及*ChunkedError
+func (s *ReportService) GetMessageDetailChunked(msgIDs []string, opts *ChunkOptions) ([]MessageDetailResponse, error) {
+	return s.GetMessageDetailChunkedContext(context.Background(), msgIDs, opts)
+}
+
+// GetMessageDetailChunkedContext 同GetMessageDetailChunked，ctx取消后不再发送新的批次
+func (s *ReportService) GetMessageDetailChunkedContext(ctx context.Context, msgIDs []string, opts *ChunkOptions) ([]MessageDetailResponse, error) {
+	return runChunked(ctx, s.client, msgIDs, opts, s.getMessageDetail)
+}
+
+// GetMessagesChunked 分批获取消息统计，msg_id数量不受100个的限制
+// opts为nil时使用默认选项；部分批次失败时返回成功批次的结果以及*ChunkedError
+func (s *ReportService) GetMessagesChunked(msgIDs []string, opts *ChunkOptions) ([]MessagesResponse, error) {
+	return s.GetMessagesChunkedContext(context.Background(), msgIDs, opts)
+}
+
+// GetMessagesChunkedContext 同GetMessagesChunked，ctx取消后不再发送新的批次
+func (s *ReportService) GetMessagesChunkedContext(ctx context.Context, msgIDs []string, opts *ChunkOptions) ([]MessagesResponse, error) {
+	return runChunked(ctx, s.client, msgIDs, opts, s.getMessages)
+}
+
+// GetMessageStatusChunked 分批查询消息送达状态（VIP功能），registration_id数量不受1000个的限制
+// opts为nil时使用默认选项；部分批次失败时返回成功批次合并后的结果以及*ChunkedError
+func (s *ReportService) GetMessageStatusChunked(req *MessageStatusRequest, opts *ChunkOptions) (MessageStatusResponse, error) {
+	return s.GetMessageStatusChunkedContext(context.Background(), req, opts)
+}
+
+// GetMessageStatusChunkedContext 同GetMessageStatusChunked，ctx取消后不再发送新的批次
+func (s *ReportService) GetMessageStatusChunkedContext(ctx context.Context, req *MessageStatusRequest, opts *ChunkOptions) (MessageStatusResponse, error) {
+	if err := validateMessageStatusRequest(req); err != nil {
+		return nil, err
+	}
+
+	fetch := func(ctx context.Context, ids []string) (MessageStatusResponse, *APIResponse, error) {
+		chunkReq := *req
+		chunkReq.RegistrationIDs = ids
+		return s.getMessageStatus(ctx, &chunkReq)
+	}
+
+	merged := make(MessageStatusResponse, len(req.RegistrationIDs))
+	err := executeChunks(ctx, s.client, req.RegistrationIDs, opts.normalize(maxStatusRegistrationIDs), fetch, func(resp MessageStatusResponse) {
+		for regID, status := range resp {
+			merged[regID] = status
+		}
+	})
+	return merged, err
+}
+
+// normalize 填充默认值并校验选项，maxSize为接口单次请求允许的最大ID数量
+func (o *ChunkOptions) normalize(maxSize int) ChunkOptions {
+	n := ChunkOptions{
+		ChunkSize:   maxSize,
+		Concurrency: defaultChunkConcurrency,
+		MaxRetries:  defaultChunkMaxRetries,
+	}
+	if o == nil {
+		return n
+	}
+	if o.ChunkSize > 0 && o.ChunkSize < maxSize {
+		n.ChunkSize = o.ChunkSize
+	}
+	if o.Concurrency > 0 {
+		n.Concurrency = o.Concurrency
+	}
+	if o.MaxRetries != 0 {
+		n.MaxRetries = o.MaxRetries
+	}
+	return n
+}
+
+// splitChunks 将ids按size切分为多个批次
+func splitChunks(ids []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(ids)+size-1)/size)
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
+
+// rateGate 在并发批次之间共享频率限制状态
+// 当剩余次数耗尽时，所有批次等待到重置时间后再继续发送
+type rateGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// wait 阻塞直到频率限制窗口重置，ctx取消时立即返回ctx的错误
+func (g *rateGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	d := time.Until(g.until)
+	g.mu.Unlock()
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pause 将等待截止时间推迟到至少now+d
+func (g *rateGate) pause(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if until := time.Now().Add(d); until.After(g.until) {
+		g.until = until
+	}
+}
+
+// observe 根据响应和错误中的频率限制信息更新等待状态，返回本次请求是否被频率限制拒绝
+// 频率限制类错误以及响应体携带其他错误码的429响应均视为频率限制
+func (g *rateGate) observe(c *Client, resp *APIResponse, err error) bool {
+	var jpushErr *JPushError
+	limited := errors.As(err, &jpushErr) &&
+		(jpushErr.Class() == ErrorClassRateLimited || jpushErr.HTTPStatus == http.StatusTooManyRequests)
+
+	_, remaining, reset := c.GetRateLimitInfo(resp)
+	exhausted := resp != nil && len(resp.Headers["X-Rate-Limit-Remaining"]) > 0 && remaining <= 0
+	if !limited && !exhausted {
+		return false
+	}
+
+	if reset <= 0 && jpushErr != nil && jpushErr.RateLimit != nil {
+		reset = jpushErr.RateLimit.Reset
+	}
+	delay := chunkRetryDelay
+	if reset > 0 {
+		delay = time.Duration(reset) * time.Second
+	}
+	g.pause(delay)
+	return limited
+}
+
+// runChunked 按批次并发执行fetch并按输入顺序合并结果
+func runChunked[T any](ctx context.Context, c *Client, msgIDs []string, opts *ChunkOptions, fetch func(context.Context, []string) ([]T, *APIResponse, error)) ([]T, error) {
+	if len(msgIDs) == 0 {
+		return nil, NewJPushError(ErrorCodeInvalidParams, "msg_ids cannot be empty")
+	}
+
+	var merged []T
+	err := executeChunks(ctx, c, msgIDs, opts.normalize(maxReportMsgIDs), fetch, func(result []T) {
+		merged = append(merged, result...)
+	})
+	return merged, err
+}
+
+// executeChunks 按批次并发执行fetch，全部完成后按批次顺序对成功结果调用merge
+// ctx取消后未开始的批次不再发送，等待频率限制重置的批次立即结束，这些批次以取消错误计入*ChunkedError
+func executeChunks[R any](ctx context.Context, c *Client, ids []string, o ChunkOptions, fetch func(context.Context, []string) (R, *APIResponse, error), merge func(R)) error {
+	chunks := splitChunks(ids, o.ChunkSize)
+	results := make([]R, len(chunks))
+	errs := make([]error, len(chunks))
+
+	gate := &rateGate{}
+	sem := make(chan struct{}, o.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = canceledChunkError(ctx)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for attempt := 0; ; attempt++ {
+				if err := gate.wait(ctx); err != nil {
+					errs[i] = canceledChunkError(ctx)
+					return
+				}
+				result, resp, err := fetch(ctx, chunk)
+				if gate.observe(c, resp, err) && attempt < o.MaxRetries {
+					continue
+				}
+				results[i], errs[i] = result, err
+				return
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	chunkedErr := &ChunkedError{Total: len(chunks)}
+	for i, err := range errs {
+		if err != nil {
+			chunkedErr.Failures = append(chunkedErr.Failures, ChunkFailure{Index: i, IDs: chunks[i], Err: err})
+			continue
+		}
+		merge(results[i])
+	}
+
+	if len(chunkedErr.Failures) > 0 {
+		c.Report.logger.Warn("分批查询部分失败",
+			"total", chunkedErr.Total,
+			"failed", len(chunkedErr.Failures))
+		return chunkedErr
+	}
+
+	return nil
+}
+
+// canceledChunkError 返回因ctx取消或超时而未完成的批次错误
+func canceledChunkError(ctx context.Context) error {
+	code, message := transportErrorCode(ctx.Err())
+	return wrapJPushError(code, message, ctx.Err())
+}
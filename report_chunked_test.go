@@ -0,0 +1,290 @@
+package goserversdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeMsgIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+	return ids
+}
+
+func TestSplitChunks(t *testing.T) {
+	chunks := splitChunks(makeMsgIDs(250), 100)
+	assert.Len(t, chunks, 3)
+	assert.Len(t, chunks[0], 100)
+	assert.Len(t, chunks[1], 100)
+	assert.Len(t, chunks[2], 50)
+	assert.Equal(t, "200", chunks[2][0])
+}
+
+func TestChunkOptions_Normalize(t *testing.T) {
+	var nilOpts *ChunkOptions
+	n := nilOpts.normalize(maxReportMsgIDs)
+	assert.Equal(t, 100, n.ChunkSize)
+	assert.Equal(t, defaultChunkConcurrency, n.Concurrency)
+	assert.Equal(t, defaultChunkMaxRetries, n.MaxRetries)
+
+	// 超过100的批次大小会被限制为100
+	n = (&ChunkOptions{ChunkSize: 500, Concurrency: 2, MaxRetries: -1}).normalize(maxReportMsgIDs)
+	assert.Equal(t, 100, n.ChunkSize)
+	assert.Equal(t, 2, n.Concurrency)
+	assert.Equal(t, -1, n.MaxRetries)
+}
+
+func TestRunChunked_MergesInOrder(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	var inFlight, maxInFlight int32
+	fetch := func(_ context.Context, ids []string) ([]string, *APIResponse, error) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&maxInFlight)
+			if cur <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, cur) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return ids, &APIResponse{}, nil
+	}
+
+	ids := makeMsgIDs(1050)
+	result, err := runChunked(context.Background(), client, ids, &ChunkOptions{Concurrency: 3}, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, ids, result)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+}
+
+func TestRunChunked_PartialFailure(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	fetch := func(_ context.Context, ids []string) ([]string, *APIResponse, error) {
+		if ids[0] == "100" {
+			return nil, nil, NewJPushError(ErrorCodeInvalidParams, "bad chunk")
+		}
+		return ids, &APIResponse{}, nil
+	}
+
+	result, err := runChunked(context.Background(), client, makeMsgIDs(250), nil, fetch)
+	assert.Error(t, err)
+	assert.Len(t, result, 150)
+
+	chunkedErr, ok := err.(*ChunkedError)
+	assert.True(t, ok)
+	assert.Equal(t, 3, chunkedErr.Total)
+	assert.Len(t, chunkedErr.Failures, 1)
+	assert.Equal(t, 1, chunkedErr.Failures[0].Index)
+	assert.Equal(t, makeMsgIDs(250)[100:200], chunkedErr.FailedIDs())
+	assert.Contains(t, chunkedErr.Error(), "1 of 3 chunks failed")
+}
+
+func TestRunChunked_RetriesOnRateLimit(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	original := chunkRetryDelay
+	chunkRetryDelay = 10 * time.Millisecond
+	defer func() { chunkRetryDelay = original }()
+
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	fetch := func(_ context.Context, ids []string) ([]string, *APIResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[ids[0]]++
+		if attempts[ids[0]] == 1 {
+			resp := &APIResponse{StatusCode: 429, Headers: map[string][]string{}}
+			return nil, resp, NewJPushError(ErrorCodeRateLimitExceeded, "rate limited")
+		}
+		return ids, &APIResponse{}, nil
+	}
+
+	result, err := runChunked(context.Background(), client, makeMsgIDs(150), nil, fetch)
+	assert.NoError(t, err)
+	assert.Len(t, result, 150)
+	assert.Equal(t, 2, attempts["0"])
+	assert.Equal(t, 2, attempts["100"])
+}
+
+func TestRunChunked_RetriesOnRateLimitClass(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	original := chunkRetryDelay
+	chunkRetryDelay = time.Millisecond
+	defer func() { chunkRetryDelay = original }()
+
+	// 携带其他错误码的429响应和广播频率限制错误码都按频率限制重试
+	limitErrs := []error{
+		&JPushError{Code: ErrorCodeInvalidParams, Message: "too many requests", HTTPStatus: http.StatusTooManyRequests},
+		&JPushError{Code: ErrorCode(2999), Message: "too many requests", HTTPStatus: http.StatusTooManyRequests},
+		NewJPushError(ErrorCodeBroadcastLimit, "broadcast limited"),
+	}
+	for _, limitErr := range limitErrs {
+		var calls int32
+		fetch := func(_ context.Context, ids []string) ([]string, *APIResponse, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return nil, nil, limitErr
+			}
+			return ids, &APIResponse{}, nil
+		}
+
+		result, err := runChunked(context.Background(), client, makeMsgIDs(10), nil, fetch)
+		assert.NoError(t, err, limitErr.Error())
+		assert.Len(t, result, 10)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	}
+}
+
+func TestRateGate_ObserveReset(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	// 未收到响应时使用错误中的频率限制信息
+	gate := &rateGate{}
+	limitErr := NewJPushError(ErrorCodeRateLimitExceeded, "rate limited")
+	limitErr.RateLimit = &RateLimitInfo{Limit: 600, Remaining: 0, Reset: 30}
+	assert.True(t, gate.observe(client, nil, limitErr))
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), gate.until, time.Second)
+
+	// 响应头中的剩余次数耗尽时等待但不视为失败
+	gate = &rateGate{}
+	resp := &APIResponse{Headers: map[string][]string{"X-Rate-Limit-Remaining": {"0"}, "X-Rate-Limit-Reset": {"5"}}}
+	assert.False(t, gate.observe(client, resp, nil))
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), gate.until, time.Second)
+
+	assert.False(t, gate.observe(client, &APIResponse{}, NewJPushError(ErrorCodeInvalidParams, "bad")))
+}
+
+func TestRunChunked_Cancel(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	fetch := func(ctx context.Context, ids []string) ([]string, *APIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		return ids, &APIResponse{}, nil
+	}
+
+	result, err := runChunked(ctx, client, makeMsgIDs(300), &ChunkOptions{Concurrency: 1}, fetch)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Len(t, result, 100)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, ErrorCodeCanceled, GetErrorCode(err))
+
+	var chunkedErr *ChunkedError
+	if assert.True(t, errors.As(err, &chunkedErr)) {
+		assert.Equal(t, makeMsgIDs(300)[100:], chunkedErr.FailedIDs())
+	}
+
+	// 等待频率限制重置时取消
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	fetch = func(_ context.Context, ids []string) ([]string, *APIResponse, error) {
+		limitErr := NewJPushError(ErrorCodeRateLimitExceeded, "rate limited")
+		limitErr.RateLimit = &RateLimitInfo{Reset: 60}
+		return nil, nil, limitErr
+	}
+	start := time.Now()
+	_, err = runChunked(ctx, client, makeMsgIDs(10), nil, fetch)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, ErrorCodeRequestTimeout, GetErrorCode(err))
+}
+
+func TestRunChunked_RetryExhausted(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	original := chunkRetryDelay
+	chunkRetryDelay = time.Millisecond
+	defer func() { chunkRetryDelay = original }()
+
+	var calls int32
+	fetch := func(_ context.Context, ids []string) ([]string, *APIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, nil, NewJPushError(ErrorCodeRateLimitExceeded, fmt.Sprintf("rate limited %s", ids[0]))
+	}
+
+	_, err = runChunked(context.Background(), client, makeMsgIDs(10), &ChunkOptions{MaxRetries: 1}, fetch)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestReportService_GetReceivedDetailChunked_InvalidParams(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	_, err = client.Report.GetReceivedDetailChunked(nil, nil)
+	assert.Error(t, err)
+
+	if jpushErr, ok := err.(*JPushError); ok {
+		assert.Equal(t, ErrorCodeInvalidParams, jpushErr.Code)
+	}
+}
+
+func TestReportService_GetMessageStatusChunked(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		var req MessageStatusRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "123", req.MsgID)
+		assert.LessOrEqual(t, len(req.RegistrationIDs), 1000)
+
+		resp := MessageStatusResponse{}
+		for _, id := range req.RegistrationIDs {
+			resp[id] = MessageStatus{Status: DeliveryStatusDelivered}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	client.baseURLs["report"] = server.URL
+
+	regIDs := makeMsgIDs(2500)
+	result, err := client.Report.GetMessageStatusChunked(&MessageStatusRequest{
+		MsgID:           "123",
+		RegistrationIDs: regIDs,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2500)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.True(t, result["2499"].Status.IsDelivered())
+}
+
+func TestChunkedError_Unwrap(t *testing.T) {
+	err := &ChunkedError{
+		Total: 2,
+		Failures: []ChunkFailure{
+			{Index: 1, IDs: []string{"1"}, Err: NewJPushError(ErrorCodeRateLimitExceeded, "rate limited")},
+		},
+	}
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, ErrorCodeRateLimitExceeded, GetErrorCode(err))
+}
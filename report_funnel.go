@@ -0,0 +1,357 @@
+package goserversdk
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// 漏斗统计中的汇总维度名称
+const (
+	FunnelCategoryNotification = "notification" // 通知
+	FunnelCategoryMessage      = "message"      // 自定义消息
+	FunnelCategoryInApp        = "inapp"        // 应用内提醒
+
+	FunnelPlatformAll      = "all"      // 所有平台汇总
+	FunnelPlatformAndroid  = "android"  // Android
+	FunnelPlatformIOS      = "ios"      // iOS
+	FunnelPlatformHMOS     = "hmos"     // 鸿蒙
+	FunnelPlatformQuickApp = "quickapp" // 快应用
+
+	FunnelChannelAll = "all" // 平台内所有通道汇总
+)
+
+// FunnelRates 推送漏斗转化率，分母为0时对应比率为0
+type FunnelRates struct {
+	DeliveryRate float64 `json:"delivery_rate"` // 送达率：Received / Sent
+	DisplayRate  float64 `json:"display_rate"`  // 展示率：Display / Received
+	ClickRate    float64 `json:"click_rate"`    // 点击率：Click / Display
+}
+
+// ChannelFunnelRow 扁平化的漏斗统计行，适合导出为CSV
+type ChannelFunnelRow struct {
+	MsgID    string `json:"msg_id"`   // 消息ID，汇总多条消息时为空
+	Category string `json:"category"` // 消息类别：notification/message/inapp
+	Platform string `json:"platform"` // 平台，all表示所有平台汇总
+	Channel  string `json:"channel"`  // 通道，all表示平台内所有通道汇总
+	ChannelStats
+	FunnelRates
+}
+
+// funnelCSVHeader CSV导出的表头，与ChannelFunnelRow.csvRecord的字段顺序一致
+var funnelCSVHeader = []string{
+	"msg_id", "category", "platform", "channel",
+	"target", "sent", "received", "display", "click",
+	"delivery_rate", "display_rate", "click_rate",
+}
+
+// Rates 计算通道的漏斗转化率
+func (c *ChannelStats) Rates() FunnelRates {
+	if c == nil {
+		return FunnelRates{}
+	}
+	return FunnelRates{
+		DeliveryRate: ratio(c.Received, c.Sent),
+		DisplayRate:  ratio(c.Display, c.Received),
+		ClickRate:    ratio(c.Click, c.Display),
+	}
+}
+
+// add 将o的各项计数累加到c
+func (c *ChannelStats) add(o *ChannelStats) {
+	c.Target += o.Target
+	c.Sent += o.Sent
+	c.Received += o.Received
+	c.Display += o.Display
+	c.Click += o.Click
+}
+
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// channelField 子通道名称与统计字段的对应关系
+type channelField struct {
+	name  string
+	stats **ChannelStats
+}
+
+// platformChannels 某个平台下的全部子通道
+type platformChannels struct {
+	platform string
+	fields   []channelField
+}
+
+func (s *AndroidSubChannels) fields() []channelField {
+	return []channelField{
+		{"jg_android", &s.JGAndroid},
+		{"huawei", &s.Huawei},
+		{"xiaomi", &s.Xiaomi},
+		{"oppo", &s.Oppo},
+		{"vivo", &s.Vivo},
+		{"meizu", &s.Meizu},
+		{"fcm", &s.FCM},
+		{"asus", &s.Asus},
+		{"tuibida", &s.Tuibida},
+		{"honor", &s.Honor},
+		{"nio", &s.Nio},
+	}
+}
+
+func (s *IOSSubChannels) fields() []channelField {
+	return []channelField{
+		{"voip", &s.VOIP},
+		{"apns", &s.APNS},
+		{"jg_ios", &s.JGIOS},
+	}
+}
+
+func (s *HMOSSubChannels) fields() []channelField {
+	return []channelField{
+		{"hmpns", &s.HMPNS},
+		{"jg_hmos", &s.JGHMOS},
+	}
+}
+
+func (s *QuickAppSubChannels) fields() []channelField {
+	return []channelField{
+		{"quick_jg", &s.QuickJG},
+		{"quick_huawei", &s.QuickHuawei},
+		{"quick_xiaomi", &s.QuickXiaomi},
+		{"quick_oppo", &s.QuickOppo},
+	}
+}
+
+// addChannelFields 按字段顺序将src累加到dst，dst中缺失的通道会被创建
+func addChannelFields(dst, src []channelField) {
+	for i := range src {
+		if *src[i].stats == nil {
+			continue
+		}
+		if *dst[i].stats == nil {
+			*dst[i].stats = &ChannelStats{}
+		}
+		(*dst[i].stats).add(*src[i].stats)
+	}
+}
+
+func addAndroidSubChannels(dst, src *AndroidSubChannels) *AndroidSubChannels {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = &AndroidSubChannels{}
+	}
+	addChannelFields(dst.fields(), src.fields())
+	return dst
+}
+
+func addIOSSubChannels(dst, src *IOSSubChannels) *IOSSubChannels {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = &IOSSubChannels{}
+	}
+	addChannelFields(dst.fields(), src.fields())
+	return dst
+}
+
+func addHMOSSubChannels(dst, src *HMOSSubChannels) *HMOSSubChannels {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = &HMOSSubChannels{}
+	}
+	addChannelFields(dst.fields(), src.fields())
+	return dst
+}
+
+func addQuickAppSubChannels(dst, src *QuickAppSubChannels) *QuickAppSubChannels {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = &QuickAppSubChannels{}
+	}
+	addChannelFields(dst.fields(), src.fields())
+	return dst
+}
+
+// add 将o累加到s
+func (s *NotificationStats) add(o *NotificationStats) {
+	s.Target += o.Target
+	s.Sent += o.Sent
+	s.Received += o.Received
+	s.Display += o.Display
+	s.Click += o.Click
+	s.SubAndroid = addAndroidSubChannels(s.SubAndroid, o.SubAndroid)
+	s.SubIOS = addIOSSubChannels(s.SubIOS, o.SubIOS)
+	s.SubHMOS = addHMOSSubChannels(s.SubHMOS, o.SubHMOS)
+	s.SubQuickApp = addQuickAppSubChannels(s.SubQuickApp, o.SubQuickApp)
+}
+
+// add 将o累加到s
+func (s *MessageStats) add(o *MessageStats) {
+	s.Target += o.Target
+	s.Sent += o.Sent
+	s.Received += o.Received
+	s.Display += o.Display
+	s.Click += o.Click
+	s.SubAndroid = addAndroidSubChannels(s.SubAndroid, o.SubAndroid)
+	s.SubIOS = addIOSSubChannels(s.SubIOS, o.SubIOS)
+	s.SubHMOS = addHMOSSubChannels(s.SubHMOS, o.SubHMOS)
+	s.SubQuickApp = addQuickAppSubChannels(s.SubQuickApp, o.SubQuickApp)
+}
+
+// add 将o累加到s
+func (s *InAppStats) add(o *InAppStats) {
+	s.Target += o.Target
+	s.Sent += o.Sent
+	s.Received += o.Received
+	s.Display += o.Display
+	s.Click += o.Click
+	s.SubAndroid = addAndroidSubChannels(s.SubAndroid, o.SubAndroid)
+	s.SubIOS = addIOSSubChannels(s.SubIOS, o.SubIOS)
+}
+
+// SumMessageDetails 汇总多条消息的统计详情，各层级计数逐项相加
+func SumMessageDetails(responses []MessageDetailResponse) *MessageDetailStats {
+	sum := &MessageDetailStats{}
+	for _, resp := range responses {
+		if resp.Details == nil {
+			continue
+		}
+		if d := resp.Details.Notification; d != nil {
+			if sum.Notification == nil {
+				sum.Notification = &NotificationStats{}
+			}
+			sum.Notification.add(d)
+		}
+		if d := resp.Details.Message; d != nil {
+			if sum.Message == nil {
+				sum.Message = &MessageStats{}
+			}
+			sum.Message.add(d)
+		}
+		if d := resp.Details.InApp; d != nil {
+			if sum.InApp == nil {
+				sum.InApp = &InAppStats{}
+			}
+			sum.InApp.add(d)
+		}
+	}
+	return sum
+}
+
+// FunnelRows 将统计详情展开为漏斗统计行
+// 每个类别依次输出：所有平台汇总行、各平台汇总行、各厂商通道行
+func (s *MessageDetailStats) FunnelRows(msgID string) []ChannelFunnelRow {
+	if s == nil {
+		return nil
+	}
+
+	var rows []ChannelFunnelRow
+	if n := s.Notification; n != nil {
+		totals := ChannelStats{Target: n.Target, Sent: n.Sent, Received: n.Received, Display: n.Display, Click: n.Click}
+		platforms := collectPlatformChannels(n.SubAndroid, n.SubIOS, n.SubHMOS, n.SubQuickApp)
+		rows = append(rows, categoryFunnelRows(msgID, FunnelCategoryNotification, totals, platforms)...)
+	}
+	if m := s.Message; m != nil {
+		totals := ChannelStats{Target: m.Target, Sent: m.Sent, Received: m.Received, Display: m.Display, Click: m.Click}
+		platforms := collectPlatformChannels(m.SubAndroid, m.SubIOS, m.SubHMOS, m.SubQuickApp)
+		rows = append(rows, categoryFunnelRows(msgID, FunnelCategoryMessage, totals, platforms)...)
+	}
+	if a := s.InApp; a != nil {
+		totals := ChannelStats{Target: a.Target, Sent: a.Sent, Received: a.Received, Display: a.Display, Click: a.Click}
+		platforms := collectPlatformChannels(a.SubAndroid, a.SubIOS, nil, nil)
+		rows = append(rows, categoryFunnelRows(msgID, FunnelCategoryInApp, totals, platforms)...)
+	}
+	return rows
+}
+
+// BuildFunnelRows 将多条消息的统计详情展开为漏斗统计行，按输入顺序排列
+func BuildFunnelRows(responses []MessageDetailResponse) []ChannelFunnelRow {
+	var rows []ChannelFunnelRow
+	for _, resp := range responses {
+		rows = append(rows, resp.Details.FunnelRows(resp.MsgID)...)
+	}
+	return rows
+}
+
+// WriteFunnelCSV 以CSV格式输出漏斗统计行（含表头）
+func WriteFunnelCSV(w io.Writer, rows []ChannelFunnelRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(funnelCSVHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := cw.Write(row.csvRecord()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r ChannelFunnelRow) csvRecord() []string {
+	return []string{
+		r.MsgID, r.Category, r.Platform, r.Channel,
+		strconv.Itoa(r.Target), strconv.Itoa(r.Sent), strconv.Itoa(r.Received),
+		strconv.Itoa(r.Display), strconv.Itoa(r.Click),
+		strconv.FormatFloat(r.DeliveryRate, 'f', 4, 64),
+		strconv.FormatFloat(r.DisplayRate, 'f', 4, 64),
+		strconv.FormatFloat(r.ClickRate, 'f', 4, 64),
+	}
+}
+
+// collectPlatformChannels 收集非空平台的子通道，顺序固定为android/ios/hmos/quickapp
+func collectPlatformChannels(android *AndroidSubChannels, ios *IOSSubChannels, hmos *HMOSSubChannels, quickApp *QuickAppSubChannels) []platformChannels {
+	var platforms []platformChannels
+	if android != nil {
+		platforms = append(platforms, platformChannels{FunnelPlatformAndroid, android.fields()})
+	}
+	if ios != nil {
+		platforms = append(platforms, platformChannels{FunnelPlatformIOS, ios.fields()})
+	}
+	if hmos != nil {
+		platforms = append(platforms, platformChannels{FunnelPlatformHMOS, hmos.fields()})
+	}
+	if quickApp != nil {
+		platforms = append(platforms, platformChannels{FunnelPlatformQuickApp, quickApp.fields()})
+	}
+	return platforms
+}
+
+func categoryFunnelRows(msgID, category string, totals ChannelStats, platforms []platformChannels) []ChannelFunnelRow {
+	newRow := func(platform, channel string, stats ChannelStats) ChannelFunnelRow {
+		return ChannelFunnelRow{
+			MsgID:        msgID,
+			Category:     category,
+			Platform:     platform,
+			Channel:      channel,
+			ChannelStats: stats,
+			FunnelRates:  stats.Rates(),
+		}
+	}
+
+	rows := []ChannelFunnelRow{newRow(FunnelPlatformAll, FunnelChannelAll, totals)}
+	for _, p := range platforms {
+		var platformTotals ChannelStats
+		var channelRows []ChannelFunnelRow
+		for _, f := range p.fields {
+			if *f.stats == nil {
+				continue
+			}
+			platformTotals.add(*f.stats)
+			channelRows = append(channelRows, newRow(p.platform, f.name, **f.stats))
+		}
+		rows = append(rows, newRow(p.platform, FunnelChannelAll, platformTotals))
+		rows = append(rows, channelRows...)
+	}
+	return rows
+}
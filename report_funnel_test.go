@@ -0,0 +1,92 @@
+package goserversdk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMessageDetail(msgID string, huaweiSent, apnsSent int) MessageDetailResponse {
+	return MessageDetailResponse{
+		MsgID: msgID,
+		Details: &MessageDetailStats{
+			Notification: &NotificationStats{
+				Target: huaweiSent + apnsSent, Sent: huaweiSent + apnsSent, Received: huaweiSent + apnsSent,
+				Display: huaweiSent + apnsSent, Click: 2,
+				SubAndroid: &AndroidSubChannels{
+					Huawei: &ChannelStats{Target: huaweiSent, Sent: huaweiSent, Received: huaweiSent / 2, Display: huaweiSent / 4, Click: 1},
+				},
+				SubIOS: &IOSSubChannels{
+					APNS: &ChannelStats{Target: apnsSent, Sent: apnsSent, Received: apnsSent, Display: apnsSent, Click: 1},
+				},
+			},
+		},
+	}
+}
+
+func TestChannelStats_Rates(t *testing.T) {
+	stats := &ChannelStats{Target: 100, Sent: 100, Received: 80, Display: 40, Click: 10}
+	rates := stats.Rates()
+	assert.InDelta(t, 0.8, rates.DeliveryRate, 1e-9)
+	assert.InDelta(t, 0.5, rates.DisplayRate, 1e-9)
+	assert.InDelta(t, 0.25, rates.ClickRate, 1e-9)
+
+	// 分母为0时比率为0
+	assert.Equal(t, FunnelRates{}, (&ChannelStats{}).Rates())
+
+	var nilStats *ChannelStats
+	assert.Equal(t, FunnelRates{}, nilStats.Rates())
+}
+
+func TestSumMessageDetails(t *testing.T) {
+	sum := SumMessageDetails([]MessageDetailResponse{
+		newTestMessageDetail("1", 100, 10),
+		newTestMessageDetail("2", 200, 20),
+		{MsgID: "3"},
+	})
+
+	assert.NotNil(t, sum.Notification)
+	assert.Nil(t, sum.Message)
+	assert.Equal(t, 330, sum.Notification.Sent)
+	assert.Equal(t, 4, sum.Notification.Click)
+	assert.Equal(t, 300, sum.Notification.SubAndroid.Huawei.Sent)
+	assert.Equal(t, 150, sum.Notification.SubAndroid.Huawei.Received)
+	assert.Nil(t, sum.Notification.SubAndroid.Xiaomi)
+	assert.Equal(t, 30, sum.Notification.SubIOS.APNS.Received)
+}
+
+func TestMessageDetailStats_FunnelRows(t *testing.T) {
+	detail := newTestMessageDetail("1", 100, 10)
+	rows := detail.Details.FunnelRows(detail.MsgID)
+
+	// 汇总行 + android汇总 + huawei + ios汇总 + apns
+	assert.Len(t, rows, 5)
+	assert.Equal(t, FunnelPlatformAll, rows[0].Platform)
+	assert.Equal(t, FunnelChannelAll, rows[0].Channel)
+	assert.Equal(t, FunnelPlatformAndroid, rows[1].Platform)
+	assert.Equal(t, FunnelChannelAll, rows[1].Channel)
+	assert.Equal(t, "huawei", rows[2].Channel)
+	assert.Equal(t, 50, rows[2].Received)
+	assert.InDelta(t, 0.5, rows[2].DeliveryRate, 1e-9)
+	assert.Equal(t, "apns", rows[4].Channel)
+	assert.Equal(t, "1", rows[4].MsgID)
+
+	var nilStats *MessageDetailStats
+	assert.Nil(t, nilStats.FunnelRows("1"))
+}
+
+func TestWriteFunnelCSV(t *testing.T) {
+	rows := BuildFunnelRows([]MessageDetailResponse{newTestMessageDetail("1", 100, 10)})
+
+	var buf bytes.Buffer
+	err := WriteFunnelCSV(&buf, rows)
+	assert.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, len(rows)+1)
+	assert.Equal(t, funnelCSVHeader, records[0])
+	assert.Equal(t, []string{"1", "notification", "android", "huawei", "100", "100", "50", "25", "1", "0.5000", "0.5000", "0.0400"}, records[3])
+}
@@ -106,7 +106,7 @@ func TestReportService_GetMessageStatus(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status": "sent", "msg_id": "123"}`))
+		w.Write([]byte(`{"test-reg-id": {"status": 0}}`))
 	}))
 	defer server.Close()
 
@@ -116,14 +116,14 @@ func TestReportService_GetMessageStatus(t *testing.T) {
 	client.baseURLs["report"] = server.URL
 
 	request := &MessageStatusRequest{
-		MsgID:           int64(123),
+		MsgID:           "123",
 		RegistrationIDs: []string{"test-reg-id"},
 		Date:            "2023-01-01",
 	}
 
 	result, err := client.Report.GetMessageStatus(request)
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
+	assert.Equal(t, DeliveryStatusDelivered, result["test-reg-id"].Status)
 }
 
 func TestReportService_GetMessageStatus_InvalidParams(t *testing.T) {
@@ -140,7 +140,7 @@ func TestReportService_GetMessageStatus_InvalidParams(t *testing.T) {
 
 	// 测试无效的msgID
 	request := &MessageStatusRequest{
-		MsgID:           int64(0),
+		MsgID:           "0",
 		RegistrationIDs: []string{"test-reg-id"},
 		Date:            "2023-01-01",
 	}
@@ -216,7 +216,7 @@ func TestReportService_InvalidJSON(t *testing.T) {
 
 	// 测试GetMessageStatus的无效JSON响应
 	request := &MessageStatusRequest{
-		MsgID:           int64(123),
+		MsgID:           "123",
 		RegistrationIDs: []string{"test-reg-id"},
 		Date:            "2023-01-01",
 	}
@@ -275,3 +275,180 @@ func TestReportService_NetworkTimeout(t *testing.T) {
 	_, err = client.Report.GetReceivedDetail(msgIDs)
 	assert.Error(t, err)
 }
+
+func TestReportService_GetMessages_InvalidParams(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	// 测试空的msgIDs
+	_, err = client.Report.GetMessages([]string{})
+	assert.Error(t, err)
+
+	if jpushErr, ok := err.(*JPushError); ok {
+		assert.Equal(t, ErrorCodeInvalidParams, jpushErr.Code)
+	}
+
+	// 测试超过100个msgIDs
+	msgIDs := make([]string, 101)
+	for i := range msgIDs {
+		msgIDs[i] = "123"
+	}
+	_, err = client.Report.GetMessages(msgIDs)
+	assert.Error(t, err)
+
+	if jpushErr, ok := err.(*JPushError); ok {
+		assert.Equal(t, ErrorCodeInvalidParams, jpushErr.Code)
+	}
+}
+
+func TestReportService_GetUserStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/users", r.URL.Path)
+		assert.Equal(t, "HOUR", r.URL.Query().Get("time_unit"))
+		assert.Equal(t, "2014-06-10 09", r.URL.Query().Get("start"))
+		assert.Equal(t, "3", r.URL.Query().Get("duration"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"time_unit": "HOUR", "start": "2014-06-10 09", "duration": 3, "items": [{"time": "2014-06-10 09", "android": {"new": 1, "online": 2, "active": 3}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	client.baseURLs["report"] = server.URL
+
+	result, err := client.Report.GetUserStats(TimeUnitHour, "2014-06-10 09", 3)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, TimeUnitHour, result.TimeUnit)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, 3, *result.Items[0].Android.Active)
+}
+
+func TestReportService_GetUserStats_InvalidParams(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		timeUnit TimeUnit
+		start    string
+		duration int
+	}{
+		{name: "unknown time unit", timeUnit: TimeUnit("WEEK"), start: "2014-06-10", duration: 1},
+		{name: "start format mismatch", timeUnit: TimeUnitDay, start: "2014-06", duration: 1},
+		{name: "zero duration", timeUnit: TimeUnitDay, start: "2014-06-10", duration: 0},
+		{name: "hour duration exceeded", timeUnit: TimeUnitHour, start: "2014-06-10 09", duration: 25},
+		{name: "month duration exceeded", timeUnit: TimeUnitMonth, start: "2014-06", duration: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Report.GetUserStats(tt.timeUnit, tt.start, tt.duration)
+			assert.Error(t, err)
+
+			if jpushErr, ok := err.(*JPushError); ok {
+				assert.Equal(t, ErrorCodeInvalidParams, jpushErr.Code)
+			}
+		})
+	}
+}
+
+func TestDeliveryStatus(t *testing.T) {
+	assert.Equal(t, "delivered", DeliveryStatusDelivered.String())
+	assert.Equal(t, "system_error", DeliveryStatusSystemError.String())
+	assert.Equal(t, "unknown(9)", DeliveryStatus(9).String())
+
+	assert.True(t, DeliveryStatusDelivered.IsDelivered())
+	assert.True(t, DeliveryStatusNotDelivered.IsNotDelivered())
+	assert.True(t, DeliveryStatusInvalidRegistrationID.IsInvalidTarget())
+	assert.True(t, DeliveryStatusNotTarget.IsInvalidTarget())
+	assert.False(t, DeliveryStatusDelivered.IsInvalidTarget())
+	assert.True(t, DeliveryStatusSystemError.IsSystemError())
+}
+
+func TestReportService_GetMessageStatus_DecodesStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"reg-1": {"status": 0}, "reg-2": {"status": 3}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	client.baseURLs["report"] = server.URL
+
+	result, err := client.Report.GetMessageStatus(&MessageStatusRequest{
+		MsgID:           "123",
+		RegistrationIDs: []string{"reg-1", "reg-2"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, result["reg-1"].Status.IsDelivered())
+	assert.Equal(t, DeliveryStatusNotTarget, result["reg-2"].Status)
+}
+
+func TestReportService_GetMessageStatus_NonNumericMsgID(t *testing.T) {
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	_, err = client.Report.GetMessageStatus(&MessageStatusRequest{
+		MsgID:           "abc",
+		RegistrationIDs: []string{"reg-1"},
+	})
+	assert.Error(t, err)
+
+	if jpushErr, ok := err.(*JPushError); ok {
+		assert.Equal(t, ErrorCodeInvalidParams, jpushErr.Code)
+	}
+}
+
+func TestReportService_GetMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/messages", r.URL.Path)
+		assert.Equal(t, "18014398509481985,2", r.URL.Query().Get("msg_ids"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"msg_id": 18014398509481985, "android": {"received": 10, "target": 12, "online_push": 8, "click": 3, "msg_click": null}, "ios": {"apns_sent": 5, "apns_target": 6}}, {"msg_id": "2", "android": {"received": 1}}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	client.baseURLs["report"] = server.URL
+
+	result, err := client.Report.GetMessages([]string{"18014398509481985", "2"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "18014398509481985", result[0].MsgID)
+	assert.Equal(t, 10, *result[0].Android.Received)
+	assert.Nil(t, result[0].Android.MsgClick)
+	assert.Equal(t, 5, *result[0].IOS.APNSSent)
+	assert.Equal(t, "2", result[1].MsgID)
+	assert.Nil(t, result[1].IOS)
+}
+
+func TestReportService_GetReceivedDetail_NumericMsgID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"msg_id": 18014398509481985, "jpush_received": 7}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewTestClient()
+	assert.NoError(t, err)
+
+	client.baseURLs["report"] = server.URL
+
+	result, err := client.Report.GetReceivedDetail([]string{"18014398509481985"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "18014398509481985", result[0].MsgID)
+	assert.Equal(t, 7, *result[0].JPushReceived)
+}
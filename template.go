@@ -0,0 +1,292 @@
+package goserversdk
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// LocaleContent 某个语言下的通知内容，各字段均为text/template模板
+type LocaleContent struct {
+	Title  string            // 通知标题，为空时不设置标题
+	Alert  string            // 通知内容
+	Extras map[string]string // 附加字段，值为模板
+}
+
+// NotificationTemplate 多语言通知模板
+//
+// 同一条通知按语言定义一次，渲染时根据用户的语言和变量生成各平台字段一致的推送请求：
+//
+//	tpl, err := NewNotificationTemplate("order-shipped", "zh-CN", map[string]LocaleContent{
+//		"zh-CN": {Title: "订单已发货", Alert: "{{.Name}}，您的订单{{.OrderID}}已发货"},
+//		"en":    {Title: "Order shipped", Alert: "{{.Name}}, your order {{.OrderID}} is on its way"},
+//		"ja":    {Title: "発送のお知らせ", Alert: "{{.Name}}様、ご注文{{.OrderID}}を発送しました"},
+//	})
+//
+// 语言的回退规则见ResolveLocale
+type NotificationTemplate struct {
+	name          string
+	defaultLocale string
+	locales       map[string]*localeTemplate
+}
+
+// localeTemplate 编译后的语言模板
+type localeTemplate struct {
+	locale string
+	title  *template.Template
+	alert  *template.Template
+	extras map[string]*template.Template
+}
+
+// RenderedNotification 渲染后的通知内容
+type RenderedNotification struct {
+	Locale string                 // 实际使用的语言
+	Title  string                 // 通知标题
+	Alert  string                 // 通知内容
+	Extras map[string]interface{} // 附加字段
+}
+
+// TemplateRecipient 模板推送的接收者
+type TemplateRecipient struct {
+	Audience *Audience   // 推送目标，通常为单个注册ID或别名
+	Locale   string      // 接收者的语言，如zh-CN、en-US
+	Vars     interface{} // 模板变量，结构体或map
+}
+
+// NewNotificationTemplate 创建多语言通知模板
+// 所有模板在创建时编译，defaultLocale必须包含在locales中
+func NewNotificationTemplate(name, defaultLocale string, locales map[string]LocaleContent) (*NotificationTemplate, error) {
+	if len(locales) == 0 {
+		return nil, NewJPushError(ErrorCodeMissingParams, "通知模板至少需要一种语言")
+	}
+
+	t := &NotificationTemplate{
+		name:          name,
+		defaultLocale: normalizeLocale(defaultLocale),
+		locales:       make(map[string]*localeTemplate, len(locales)),
+	}
+	for locale, content := range locales {
+		key := normalizeLocale(locale)
+		if key == "" {
+			return nil, NewJPushError(ErrorCodeInvalidParams, "通知模板的语言不能为空")
+		}
+		if _, ok := t.locales[key]; ok {
+			return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("通知模板的语言%s重复定义", locale))
+		}
+		if content.Alert == "" {
+			return nil, NewJPushError(ErrorCodeMissingParams, fmt.Sprintf("通知模板语言%s缺少通知内容", locale))
+		}
+
+		lt := &localeTemplate{locale: locale, extras: make(map[string]*template.Template, len(content.Extras))}
+		var err error
+		if lt.title, err = t.parse(locale, "title", content.Title); err != nil {
+			return nil, err
+		}
+		if lt.alert, err = t.parse(locale, "alert", content.Alert); err != nil {
+			return nil, err
+		}
+		for key, value := range content.Extras {
+			if lt.extras[key], err = t.parse(locale, "extras."+key, value); err != nil {
+				return nil, err
+			}
+		}
+		t.locales[key] = lt
+	}
+
+	if _, ok := t.locales[t.defaultLocale]; !ok {
+		return nil, NewJPushError(ErrorCodeInvalidParams, fmt.Sprintf("默认语言%s没有定义通知内容", defaultLocale))
+	}
+	return t, nil
+}
+
+// parse 编译单个字段的模板，引用不存在的变量时渲染失败
+func (t *NotificationTemplate) parse(locale, field, text string) (*template.Template, error) {
+	tpl, err := template.New(t.name + "/" + locale + "/" + field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, wrapJPushError(ErrorCodeInvalidParams, fmt.Sprintf("通知模板语言%s的%s格式错误", locale, field), err)
+	}
+	return tpl, nil
+}
+
+// Name 返回模板名称
+func (t *NotificationTemplate) Name() string {
+	return t.name
+}
+
+// Locales 返回模板定义的语言，按字母顺序排列
+func (t *NotificationTemplate) Locales() []string {
+	locales := make([]string, 0, len(t.locales))
+	for _, lt := range t.locales {
+		locales = append(locales, lt.locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// ResolveLocale 返回渲染指定语言时实际使用的模板语言
+//
+// 语言标签不区分大小写，下划线等同于连字符。依次尝试：
+//  1. 完全匹配，如zh-CN
+//  2. 逐级去掉末尾的子标签，如zh-Hant-TW依次尝试zh-Hant、zh
+//  3. 同一语种下的其他地区，如请求zh-TW时使用zh-CN，有多个时取字母顺序第一个
+//  4. 默认语言
+func (t *NotificationTemplate) ResolveLocale(locale string) string {
+	return t.resolve(locale).locale
+}
+
+// resolve 按回退规则查找语言模板
+func (t *NotificationTemplate) resolve(locale string) *localeTemplate {
+	key := normalizeLocale(locale)
+	for key != "" {
+		if lt, ok := t.locales[key]; ok {
+			return lt
+		}
+		i := strings.LastIndexByte(key, '-')
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+
+	if key != "" {
+		// 按规范化后的语言标签比较，不受原始标签的大小写和分隔符影响
+		var match string
+		for candidate := range t.locales {
+			if strings.HasPrefix(candidate, key+"-") && (match == "" || candidate < match) {
+				match = candidate
+			}
+		}
+		if match != "" {
+			return t.locales[match]
+		}
+	}
+	return t.locales[t.defaultLocale]
+}
+
+// Render 按语言回退规则渲染通知内容
+func (t *NotificationTemplate) Render(locale string, vars interface{}) (*RenderedNotification, error) {
+	lt := t.resolve(locale)
+
+	rendered := &RenderedNotification{Locale: lt.locale}
+	var err error
+	if rendered.Title, err = executeTemplate(lt.title, vars); err != nil {
+		return nil, err
+	}
+	if rendered.Alert, err = executeTemplate(lt.alert, vars); err != nil {
+		return nil, err
+	}
+	if rendered.Alert == "" {
+		return nil, NewJPushError(ErrorCodeInvalidNotification, fmt.Sprintf("通知模板%s渲染后的内容为空", lt.alert.Name()))
+	}
+	if len(lt.extras) > 0 {
+		rendered.Extras = make(map[string]interface{}, len(lt.extras))
+		for key, tpl := range lt.extras {
+			value, err := executeTemplate(tpl, vars)
+			if err != nil {
+				return nil, err
+			}
+			rendered.Extras[key] = value
+		}
+	}
+	return rendered, nil
+}
+
+// executeTemplate 执行模板并返回结果
+func executeTemplate(tpl *template.Template, vars interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", wrapJPushError(ErrorCodeInvalidParams, fmt.Sprintf("渲染通知模板%s失败", tpl.Name()), err)
+	}
+	return buf.String(), nil
+}
+
+// Notification 将渲染结果填充到各平台的通知字段
+// 未指定平台时填充android、ios和hmos，快应用需要显式指定quickapp
+func (r *RenderedNotification) Notification(platforms ...string) (*Notification, error) {
+	if len(platforms) == 0 {
+		platforms = []string{PlatformAndroid, PlatformIOS, PlatformHMOS}
+	}
+
+	var title *string
+	if r.Title != "" {
+		t := r.Title
+		title = &t
+	}
+	notification := &Notification{Alert: r.Alert}
+	for _, platform := range platforms {
+		switch platform {
+		case PlatformAndroid:
+			notification.Android = &AndroidNotification{Alert: r.Alert, Title: title, Extras: r.copyExtras()}
+		case PlatformIOS:
+			ios := &IOSNotification{Alert: r.Alert, Extras: r.copyExtras()}
+			if title != nil {
+				ios.Alert = map[string]interface{}{"title": r.Title, "body": r.Alert}
+			}
+			notification.IOS = ios
+		case PlatformHMOS:
+			notification.HMOS = &HMOSNotification{Alert: r.Alert, Title: title, Extras: r.copyExtras()}
+		case PlatformQuickApp:
+			notification.QuickApp = &QuickAppNotification{Alert: r.Alert, Title: title, Extras: r.copyExtras()}
+		default:
+			return nil, NewJPushError(ErrorCodeInvalidPlatform, fmt.Sprintf("通知模板不支持平台%s", platform))
+		}
+	}
+	return notification, nil
+}
+
+// copyExtras 为每个平台复制附加字段，避免修改一个平台影响其他平台
+func (r *RenderedNotification) copyExtras() map[string]interface{} {
+	if len(r.Extras) == 0 {
+		return nil
+	}
+	extras := make(map[string]interface{}, len(r.Extras))
+	for k, v := range r.Extras {
+		extras[k] = v
+	}
+	return extras
+}
+
+// PushRequest 渲染模板并构造推送请求
+// 指定平台时请求只推送到这些平台，否则推送到所有平台
+func (t *NotificationTemplate) PushRequest(locale string, vars interface{}, audience *Audience, platforms ...string) (*PushRequest, error) {
+	rendered, err := t.Render(locale, vars)
+	if err != nil {
+		return nil, err
+	}
+	notification, err := rendered.Notification(platforms...)
+	if err != nil {
+		return nil, err
+	}
+
+	platform := NewAllPlatform()
+	if len(platforms) > 0 {
+		platform = NewSpecificPlatforms(platforms...)
+	}
+	return NewPushRequest().
+		SetPlatform(platform).
+		SetAudience(audience).
+		SetNotification(notification), nil
+}
+
+// PushRequests 为每个接收者按其语言和变量构造单独的推送请求，顺序与recipients一致
+func (t *NotificationTemplate) PushRequests(recipients []TemplateRecipient, platforms ...string) ([]*PushRequest, error) {
+	requests := make([]*PushRequest, 0, len(recipients))
+	for i, recipient := range recipients {
+		if recipient.Audience == nil {
+			return nil, NewJPushError(ErrorCodeMissingParams, fmt.Sprintf("第%d个接收者缺少推送目标", i+1))
+		}
+		req, err := t.PushRequest(recipient.Locale, recipient.Vars, recipient.Audience, platforms...)
+		if err != nil {
+			return nil, wrapJPushError(GetErrorCode(err), fmt.Sprintf("第%d个接收者的推送请求构造失败", i+1), err)
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// normalizeLocale 将语言标签统一为小写、连字符分隔的形式
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,158 @@
+package goserversdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newOrderTemplate 创建测试用的多语言订单模板
+func newOrderTemplate(t *testing.T) *NotificationTemplate {
+	t.Helper()
+	tpl, err := NewNotificationTemplate("order-shipped", "zh-CN", map[string]LocaleContent{
+		"zh-CN": {
+			Title:  "订单已发货",
+			Alert:  "{{.Name}}，您的订单{{.OrderID}}已发货",
+			Extras: map[string]string{"url": "app://order/{{.OrderID}}"},
+		},
+		"zh-TW": {Title: "訂單已出貨", Alert: "{{.Name}}，您的訂單{{.OrderID}}已出貨"},
+		"en":    {Title: "Order shipped", Alert: "{{.Name}}, your order {{.OrderID}} is on its way"},
+		"ja_JP": {Alert: "{{.Name}}様、ご注文{{.OrderID}}を発送しました"},
+	})
+	assert.NoError(t, err)
+	return tpl
+}
+
+func TestNotificationTemplate_ResolveLocale(t *testing.T) {
+	tpl := newOrderTemplate(t)
+	assert.Equal(t, "order-shipped", tpl.Name())
+	assert.Equal(t, []string{"en", "ja_JP", "zh-CN", "zh-TW"}, tpl.Locales())
+
+	tests := map[string]string{
+		"zh-CN":      "zh-CN",
+		"zh_cn":      "zh-CN",
+		"zh-Hant-TW": "zh-CN", // zh-hant、zh都未定义，取同语种中字母顺序第一个
+		"zh-TW":      "zh-TW",
+		"en-US":      "en",
+		"EN":         "en",
+		"ja":         "ja_JP",
+		"ja-JP":      "ja_JP",
+		"fr-FR":      "zh-CN",
+		"":           "zh-CN",
+	}
+	for locale, want := range tests {
+		assert.Equal(t, want, tpl.ResolveLocale(locale), locale)
+	}
+
+	// 同语种回退按规范化后的标签排序，与原始标签的大小写和分隔符无关
+	tpl, err := NewNotificationTemplate("t", "en", map[string]LocaleContent{
+		"en":    {Alert: "hi"},
+		"zh-TW": {Alert: "嗨"},
+		"zh_cn": {Alert: "嗨"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "zh_cn", tpl.ResolveLocale("zh-HK"))
+}
+
+func TestNotificationTemplate_Render(t *testing.T) {
+	tpl := newOrderTemplate(t)
+	vars := map[string]interface{}{"Name": "Alice", "OrderID": "A100"}
+
+	rendered, err := tpl.Render("en-GB", vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "en", rendered.Locale)
+	assert.Equal(t, "Order shipped", rendered.Title)
+	assert.Equal(t, "Alice, your order A100 is on its way", rendered.Alert)
+	assert.Nil(t, rendered.Extras)
+
+	// 结构体变量
+	rendered, err = tpl.Render("zh-CN", struct{ Name, OrderID string }{"张三", "B200"})
+	assert.NoError(t, err)
+	assert.Equal(t, "张三，您的订单B200已发货", rendered.Alert)
+	assert.Equal(t, map[string]interface{}{"url": "app://order/B200"}, rendered.Extras)
+
+	// 缺少变量
+	_, err = tpl.Render("ja", map[string]interface{}{"Name": "Alice"})
+	assert.Error(t, err)
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+}
+
+func TestNotificationTemplate_PushRequest(t *testing.T) {
+	tpl := newOrderTemplate(t)
+	vars := map[string]interface{}{"Name": "Alice", "OrderID": "A100"}
+
+	req, err := tpl.PushRequest("zh-CN", vars, NewRegistrationIDAudience("reg-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "all", req.Platform)
+	assert.Equal(t, []string{"reg-1"}, req.Audience.RegistrationID)
+
+	n := req.Notification
+	assert.Equal(t, "Alice，您的订单A100已发货", n.Alert)
+	assert.Equal(t, n.Alert, n.Android.Alert)
+	assert.Equal(t, "订单已发货", *n.Android.Title)
+	assert.Equal(t, map[string]interface{}{"title": "订单已发货", "body": n.Alert}, n.IOS.Alert)
+	assert.Equal(t, "订单已发货", *n.HMOS.Title)
+	assert.Nil(t, n.QuickApp)
+	assert.Equal(t, "app://order/A100", n.Android.Extras["url"])
+	assert.Equal(t, "app://order/A100", n.IOS.Extras["url"])
+
+	// 各平台的附加字段相互独立
+	n.Android.Extras["url"] = "changed"
+	assert.Equal(t, "app://order/A100", n.HMOS.Extras["url"])
+	assert.NoError(t, (&PushService{}).validatePushRequest(req))
+
+	// 没有标题时iOS使用字符串内容
+	req, err = tpl.PushRequest("ja", vars, NewAliasAudience("alice"), PlatformIOS, PlatformQuickApp)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{PlatformIOS, PlatformQuickApp}, req.Platform)
+	assert.Equal(t, "Alice様、ご注文A100を発送しました", req.Notification.IOS.Alert)
+	assert.Nil(t, req.Notification.QuickApp.Title)
+	assert.Nil(t, req.Notification.Android)
+
+	_, err = tpl.PushRequest("en", vars, NewBroadcastAudience(), PlatformWinPhone)
+	assert.Equal(t, ErrorCodeInvalidPlatform, GetErrorCode(err))
+}
+
+func TestNotificationTemplate_PushRequests(t *testing.T) {
+	tpl := newOrderTemplate(t)
+
+	reqs, err := tpl.PushRequests([]TemplateRecipient{
+		{Audience: NewRegistrationIDAudience("reg-1"), Locale: "en-US", Vars: map[string]string{"Name": "Alice", "OrderID": "A1"}},
+		{Audience: NewRegistrationIDAudience("reg-2"), Locale: "ja-JP", Vars: map[string]string{"Name": "花子", "OrderID": "A2"}},
+	}, PlatformAndroid)
+	assert.NoError(t, err)
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, "Alice, your order A1 is on its way", reqs[0].Notification.Android.Alert)
+		assert.Equal(t, []string{"reg-2"}, reqs[1].Audience.RegistrationID)
+		assert.Equal(t, "花子様、ご注文A2を発送しました", reqs[1].Notification.Android.Alert)
+	}
+
+	_, err = tpl.PushRequests([]TemplateRecipient{{Locale: "en"}})
+	assert.Equal(t, ErrorCodeMissingParams, GetErrorCode(err))
+
+	_, err = tpl.PushRequests([]TemplateRecipient{{Audience: NewAliasAudience("a"), Vars: map[string]string{}}})
+	assert.Equal(t, ErrorCodeInvalidParams, GetErrorCode(err))
+}
+
+func TestNewNotificationTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultLocale string
+		locales       map[string]LocaleContent
+		code          ErrorCode
+	}{
+		{"没有语言", "en", nil, ErrorCodeMissingParams},
+		{"缺少内容", "en", map[string]LocaleContent{"en": {Title: "hi"}}, ErrorCodeMissingParams},
+		{"默认语言未定义", "fr", map[string]LocaleContent{"en": {Alert: "hi"}}, ErrorCodeInvalidParams},
+		{"重复语言", "en", map[string]LocaleContent{"en-US": {Alert: "a"}, "en_us": {Alert: "b"}}, ErrorCodeInvalidParams},
+		{"模板语法", "en", map[string]LocaleContent{"en": {Alert: "{{.Name"}}, ErrorCodeInvalidParams},
+		{"附加字段语法", "en", map[string]LocaleContent{"en": {Alert: "hi", Extras: map[string]string{"k": "{{end}}"}}}, ErrorCodeInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNotificationTemplate("t", tt.defaultLocale, tt.locales)
+			assert.Error(t, err)
+			assert.Equal(t, tt.code, GetErrorCode(err))
+		})
+	}
+}
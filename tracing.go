@@ -0,0 +1,234 @@
+package goserversdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 常用span属性名
+const (
+	AttrEndpoint     = "jpush.endpoint"      // 归一化后的请求路径
+	AttrHTTPMethod   = "http.method"         // HTTP方法
+	AttrHTTPStatus   = "http.status_code"    // HTTP状态码
+	AttrErrorCode    = "jpush.error_code"    // JPush错误码
+	AttrMsgID        = "jpush.msg_id"        // 消息ID
+	AttrMsgIDCount   = "jpush.msg_id_count"  // 查询的消息ID数量
+	AttrSendNo       = "jpush.sendno"        // 推送序号
+	AttrAudienceType = "jpush.audience_type" // 推送目标类型，如all、tag、alias,registration_id
+	AttrDryRun       = "jpush.dry_run"       // 试运行模式，如validate、local
+)
+
+// traceParentHeader W3C Trace Context请求头
+const traceParentHeader = "traceparent"
+
+// Tracer 链路追踪接口，可基于OpenTelemetry等实现
+type Tracer interface {
+	// StartSpan 开始一个span，父span应从ctx中获取，返回的ctx需携带新span
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span 链路追踪span
+type Span interface {
+	// SetAttribute 设置属性
+	SetAttribute(key string, value interface{})
+	// TraceParent 返回注入请求头的W3C traceparent值，返回空字符串时不注入
+	TraceParent() string
+	// End 结束span，err为nil表示成功
+	End(err error)
+}
+
+// FormatTraceParent 按W3C Trace Context格式生成traceparent值
+// traceID为32位、spanID为16位小写十六进制字符串
+func FormatTraceParent(traceID, spanID string, sampled bool) string {
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	return "00-" + traceID + "-" + spanID + "-" + flags
+}
+
+// TracingMiddleware 创建为每次HTTP请求生成span并注入traceparent请求头的中间件
+// 通过Config.Tracer设置时会自动注册
+func TracingMiddleware(tracer Tracer) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *Request) (*APIResponse, error) {
+			endpoint := normalizeEndpoint(req.Path)
+			ctx, span := tracer.StartSpan(ctx, "HTTP "+req.Method+" "+endpoint)
+			span.SetAttribute(AttrHTTPMethod, req.Method)
+			span.SetAttribute(AttrEndpoint, endpoint)
+			if tp := span.TraceParent(); tp != "" {
+				req.Header.Set(traceParentHeader, tp)
+			}
+
+			resp, err := next(ctx, req)
+			if resp != nil {
+				span.SetAttribute(AttrHTTPStatus, resp.StatusCode)
+			}
+			endSpan(span, err)
+			return resp, err
+		}
+	}
+}
+
+// endSpan 记录错误码并结束span
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.SetAttribute(AttrErrorCode, int(GetErrorCode(err)))
+	}
+	span.End(err)
+}
+
+// startSpan 开始服务级span，未配置Tracer时返回空实现
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, nopSpan{}
+	}
+	return c.tracer.StartSpan(ctx, name)
+}
+
+// audienceType 返回推送目标类型，多种目标组合时以逗号分隔
+func audienceType(a *Audience) string {
+	if a == nil {
+		return ""
+	}
+	if a.All != nil {
+		return "all"
+	}
+	var types []string
+	add := func(name string, n int) {
+		if n > 0 {
+			types = append(types, name)
+		}
+	}
+	add("tag", len(a.Tag))
+	add("tag_and", len(a.TagAnd))
+	add("tag_not", len(a.TagNot))
+	add("alias", len(a.Alias))
+	add("registration_id", len(a.RegistrationID))
+	add("segment", len(a.Segment))
+	add("abtest", len(a.ABTest))
+	if a.LiveActivityID != nil {
+		types = append(types, "live_activity_id")
+	}
+	return strings.Join(types, ",")
+}
+
+// nopSpan 空span
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, interface{}) {}
+func (nopSpan) TraceParent() string              { return "" }
+func (nopSpan) End(error)                        {}
+
+// RecordedSpan MemoryTracer记录的span
+type RecordedSpan struct {
+	Name         string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string // 根span为空
+	Attributes   map[string]interface{}
+	Err          error
+	StartTime    time.Time
+	EndTime      time.Time
+}
+
+// MemoryTracer 在内存中记录span的Tracer实现，主要用于测试
+type MemoryTracer struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+// NewMemoryTracer 创建内存Tracer
+func NewMemoryTracer() *MemoryTracer {
+	return &MemoryTracer{}
+}
+
+// memorySpanKey ctx中保存当前memorySpan的键
+type memorySpanKey struct{}
+
+// StartSpan 开始span，ctx中存在MemoryTracer的span时作为其子span
+func (t *MemoryTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := &memorySpan{
+		tracer: t,
+		record: RecordedSpan{
+			Name:       name,
+			SpanID:     randomHex(8),
+			Attributes: make(map[string]interface{}),
+			StartTime:  time.Now(),
+		},
+	}
+	if parent, ok := ctx.Value(memorySpanKey{}).(*memorySpan); ok {
+		span.record.TraceID = parent.record.TraceID
+		span.record.ParentSpanID = parent.record.SpanID
+	} else {
+		span.record.TraceID = randomHex(16)
+	}
+	return context.WithValue(ctx, memorySpanKey{}, span), span
+}
+
+// Spans 返回已结束的span，按结束顺序排列
+func (t *MemoryTracer) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]RecordedSpan(nil), t.spans...)
+}
+
+// FindSpan 返回第一个名称匹配的已结束span
+func (t *MemoryTracer) FindSpan(name string) (RecordedSpan, bool) {
+	for _, s := range t.Spans() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return RecordedSpan{}, false
+}
+
+// memorySpan MemoryTracer的span
+type memorySpan struct {
+	tracer *MemoryTracer
+	mu     sync.Mutex
+	record RecordedSpan
+	ended  bool
+}
+
+func (s *memorySpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record.Attributes[key] = value
+}
+
+func (s *memorySpan) TraceParent() string {
+	return FormatTraceParent(s.record.TraceID, s.record.SpanID, true)
+}
+
+func (s *memorySpan) End(err error) {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.record.Err = err
+	s.record.EndTime = time.Now()
+	record := s.record
+	record.Attributes = make(map[string]interface{}, len(s.record.Attributes))
+	for k, v := range s.record.Attributes {
+		record.Attributes[k] = v
+	}
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	s.tracer.spans = append(s.tracer.spans, record)
+	s.tracer.mu.Unlock()
+}
+
+// randomHex 生成n字节的随机十六进制字符串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
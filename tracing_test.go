@@ -0,0 +1,106 @@
+package goserversdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var traceParentPattern = regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`)
+
+func TestMemoryTracer_ParentChild(t *testing.T) {
+	tracer := NewMemoryTracer()
+	ctx, parent := tracer.StartSpan(context.Background(), "parent")
+	_, child := tracer.StartSpan(ctx, "child")
+	child.SetAttribute("k", "v")
+	child.End(nil)
+	parent.End(nil)
+	parent.End(nil) // 重复结束不会重复记录
+
+	spans := tracer.Spans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Equal(t, "v", spans[0].Attributes["k"])
+	assert.Regexp(t, traceParentPattern, child.TraceParent())
+}
+
+func TestAudienceType(t *testing.T) {
+	assert.Equal(t, "all", audienceType(NewBroadcastAudience()))
+	assert.Equal(t, "tag,alias", audienceType(&Audience{Tag: []string{"a"}, Alias: []string{"b"}}))
+	assert.Equal(t, "", audienceType(nil))
+}
+
+func TestClient_TracingPush(t *testing.T) {
+	var gotTraceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceParent = r.Header.Get("traceparent")
+		w.Write([]byte(`{"sendno":"7","msg_id":"18100000012345678"}`))
+	}))
+	defer server.Close()
+
+	tracer := NewMemoryTracer()
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Push: server.URL},
+		Tracer:       tracer,
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Push.Push(&PushRequest{
+		Platform:     NewAllPlatform().GetPlatforms(),
+		Audience:     NewRegistrationIDAudience("1a0018970a8b2c3d"),
+		Notification: &Notification{Alert: "hello"},
+	})
+	assert.NoError(t, err)
+
+	pushSpan, ok := tracer.FindSpan("jpush.Push")
+	assert.True(t, ok)
+	assert.Equal(t, "registration_id", pushSpan.Attributes[AttrAudienceType])
+	assert.Equal(t, "7", pushSpan.Attributes[AttrSendNo])
+	assert.Equal(t, "18100000012345678", pushSpan.Attributes[AttrMsgID])
+	assert.Nil(t, pushSpan.Err)
+
+	httpSpan, ok := tracer.FindSpan("HTTP POST /v3/push")
+	assert.True(t, ok)
+	assert.Equal(t, pushSpan.SpanID, httpSpan.ParentSpanID)
+	assert.Equal(t, http.StatusOK, httpSpan.Attributes[AttrHTTPStatus])
+	assert.Equal(t, FormatTraceParent(httpSpan.TraceID, httpSpan.SpanID, true), gotTraceParent)
+}
+
+func TestClient_TracingGetMessageDetailError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":2006,"message":"not vip"}}`))
+	}))
+	defer server.Close()
+
+	tracer := NewMemoryTracer()
+	client, err := NewClient(&Config{
+		AppKey:       "app-key",
+		MasterSecret: "master-secret",
+		BaseURLs:     &BaseURLs{Report: server.URL},
+		Tracer:       tracer,
+	})
+	assert.NoError(t, err)
+
+	_, err = client.Report.GetMessageDetail([]string{"123"})
+	assert.Error(t, err)
+
+	span, ok := tracer.FindSpan("jpush.GetMessageDetail")
+	assert.True(t, ok)
+	assert.Equal(t, "123", span.Attributes[AttrMsgID])
+	assert.Equal(t, int(ErrorCodeNotVIP), span.Attributes[AttrErrorCode])
+	assert.Error(t, span.Err)
+
+	httpSpan, ok := tracer.FindSpan("HTTP GET /v3/messages/detail")
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, httpSpan.Attributes[AttrHTTPStatus])
+}
@@ -1,7 +1,58 @@
 package goserversdk
 
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
 // ToPtr converts a value to a pointer.
 // Avoid passing pointers to this function.
 func ToPtr[T any](v T) *T {
 	return &v
 }
+
+// buildPath 为请求路径附加查询参数
+// 参数按名称排序并进行URL编码，相同的输入总是生成相同的请求路径
+func buildPath(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// joinPath 将路径片段逐段转义后拼接到prefix之后
+func joinPath(prefix string, segments ...string) string {
+	escaped := make([]string, 0, len(segments)+1)
+	escaped = append(escaped, strings.TrimSuffix(prefix, "/"))
+	for _, segment := range segments {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	return strings.Join(escaped, "/")
+}
+
+// flexibleID 兼容字符串和数字两种JSON形式的ID
+// 数字形式按原始文本保留，不经过float64转换，因此不会丢失大整数精度
+type flexibleID string
+
+func (id *flexibleID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = flexibleID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = flexibleID(n)
+	return nil
+}
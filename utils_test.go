@@ -0,0 +1,28 @@
+package goserversdk
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPath(t *testing.T) {
+	assert.Equal(t, "/v3/push/cid", buildPath("/v3/push/cid", nil))
+
+	// 参数按名称排序，与插入顺序无关
+	query := url.Values{}
+	query.Set("type", "push")
+	query.Set("count", "3")
+	assert.Equal(t, "/v3/push/cid?count=3&type=push", buildPath("/v3/push/cid", query))
+
+	// 特殊字符会被编码
+	path := buildPath("/v3/received", url.Values{"msg_ids": {"1,2&x=3"}})
+	assert.Equal(t, "/v3/received?msg_ids=1%2C2%26x%3D3", path)
+}
+
+func TestJoinPath(t *testing.T) {
+	assert.Equal(t, "/v3/push/123", joinPath("/v3/push", "123"))
+	assert.Equal(t, "/v3/push/123", joinPath("/v3/push/", "123"))
+	assert.Equal(t, "/v3/push/a%2Fb%3Fc", joinPath("/v3/push", "a/b?c"))
+}